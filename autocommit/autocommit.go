@@ -0,0 +1,225 @@
+// Package autocommit commits accepted solutions to the git repository they are
+// in, optionally keeping an index of them in its README
+package autocommit
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/team"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const DefaultMessage = "Solve {title} ({provider}, {difficulty}) in {lang}"
+
+// Trailers recorded in commits, along with team.VerdictTrailer
+const (
+	ProblemTrailer    string = "Tinycode-Problem"
+	TitleTrailer             = "Tinycode-Title"
+	DifficultyTrailer        = "Tinycode-Difficulty"
+	LangTrailer              = "Tinycode-Lang"
+	PathTrailer              = "Tinycode-Path"
+)
+
+// Config enables commits of accepted solutions, e.g.
+//
+//	[git]
+//	commit = true
+//	message = "{provider}: {title}"
+//	readme = true
+type Config struct {
+	Commit  bool   `mapstructure:"commit"`
+	Message string `mapstructure:"message"` // DefaultMessage if empty
+	Readme  bool   `mapstructure:"readme"`
+}
+
+// Merge returns config with what other enables or sets on top of it
+func (config Config) Merge(other Config) Config {
+	config.Commit = config.Commit || other.Commit
+	config.Readme = config.Readme || other.Readme
+	if other.Message != "" {
+		config.Message = other.Message
+	}
+	return config
+}
+
+// Solution is an accepted solution, and the files which come with it
+type Solution struct {
+	Path       string // of the solution itself
+	Files      []string
+	Provider   string
+	Identity   provider.Filters
+	Details    provider.ChallengeDetails
+	Lang       provider.Lang
+	Statistics provider.SubmissionStatistics
+}
+
+func (solution *Solution) problem() string {
+	return fmt.Sprintf("%s/%s/%s", solution.Provider, solution.Identity.GetFilterOrDefault("contest"), solution.Identity.GetFilterOrDefault("slug"))
+}
+
+func formatPercentile(p float64) string {
+	if math.IsNaN(p) {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", p)
+}
+
+// vars are the values of the placeholders of commit messages
+func (solution *Solution) vars() map[string]string {
+	vars := map[string]string{
+		"provider":           solution.Provider,
+		"title":              solution.Details.Title,
+		"difficulty":         strings.ToLower(solution.Details.Difficulty),
+		"lang":               solution.Lang.String(),
+		"runtime":            solution.Statistics.Runtime,
+		"runtime_percentile": formatPercentile(solution.Statistics.RuntimePercentile),
+		"memory":             solution.Statistics.Memory,
+		"memory_percentile":  formatPercentile(solution.Statistics.MemoryPercentile),
+		"score":              solution.Statistics.Score,
+		"max_score":          solution.Statistics.MaxScore,
+	}
+	for name, value := range solution.Identity.Map() {
+		vars[name] = value
+	}
+	if vars["title"] == "" {
+		vars["title"] = vars["slug"]
+	}
+	for name, value := range vars {
+		if value == "" {
+			vars[name] = "n/a"
+		}
+	}
+	return vars
+}
+
+// statistics describes what is known of the performance of solution, e.g.
+// "Runtime: 4 ms (better than 92.1%)"
+func (solution *Solution) statistics() string {
+	var lines []string
+	line := func(label string, value string, percentile float64) {
+		if value == "" {
+			return
+		}
+		if p := formatPercentile(percentile); p != "" {
+			value = fmt.Sprintf("%s (better than %s)", value, p)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", label, value))
+	}
+
+	stats := solution.Statistics
+	line("Runtime", stats.Runtime, stats.RuntimePercentile)
+	line("Memory", stats.Memory, stats.MemoryPercentile)
+	if stats.MaxScore != "" {
+		line("Score", fmt.Sprintf("%s/%s", stats.Score, stats.MaxScore), math.NaN())
+	} else {
+		line("Score", stats.Score, math.NaN())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// trailers are recorded in the commit of solution, at path relative to the
+// root of the repository
+func (solution *Solution) trailers(path string) [][2]string {
+	return [][2]string{
+		{team.VerdictTrailer, string(team.Accepted)},
+		{ProblemTrailer, solution.problem()},
+		{TitleTrailer, solution.Details.Title},
+		{DifficultyTrailer, solution.Details.Difficulty},
+		{LangTrailer, solution.Lang.String()},
+		{PathTrailer, path},
+	}
+}
+
+// Message expands template with the placeholders of solution, e.g. `{title}`,
+// and appends the trailers recognized by `tinycode team`. The default message
+// comes with the statistics of the submission.
+func (solution *Solution) Message(template string, path string) string {
+	if template == "" {
+		template = DefaultMessage
+		if stats := solution.statistics(); stats != "" {
+			template += "\n\n" + stats
+		}
+	}
+
+	vars := solution.vars()
+	re := regexp.MustCompile("{(\\w+)}")
+	message := re.ReplaceAllStringFunc(template, func(m string) string {
+		if value, ok := vars[m[1:len(m)-1]]; ok {
+			return value
+		}
+		return m
+	})
+
+	var buf strings.Builder
+	buf.WriteString(strings.TrimRight(message, "\n"))
+	buf.WriteString("\n\n")
+	for _, trailer := range solution.trailers(path) {
+		if value := strings.ReplaceAll(trailer[1], "\n", " "); value != "" {
+			fmt.Fprintf(&buf, "%s: %s\n", trailer[0], value)
+		}
+	}
+	return buf.String()
+}
+
+// Commit commits the files of solution to the repository they are in, with
+// the README index if config says so. It does nothing if they have not changed
+// since the last commit.
+func Commit(config Config, solution Solution) error {
+	root, err := team.Toplevel(filepath.Dir(solution.Path))
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(root, solution.Path)
+	if err != nil {
+		return err
+	}
+
+	paths := append([]string{solution.Path}, solution.Files...)
+	if _, err := team.Git(root, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+
+	status, err := team.Git(root, append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(status))) == 0 {
+		log.Printf("%s has not changed since it was last committed", solution.Path)
+		return nil
+	}
+
+	message := solution.Message(config.Message, filepath.ToSlash(rel))
+
+	if config.Readme {
+		readme, err := UpdateReadme(root, solution, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if _, err := team.Git(root, "add", "--", readme); err != nil {
+			return err
+		}
+		paths = append(paths, readme)
+	}
+
+	f, err := os.CreateTemp("", "tinycode-commit-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	// only the paths of the solution are committed, whatever else is staged
+	_, err = team.Git(root, append([]string{"commit", "--quiet", "--cleanup=strip", "--file", f.Name(), "--"}, paths...)...)
+	return err
+}
@@ -0,0 +1,133 @@
+package autocommit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/team"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ReadmeFilename = "README.md"
+
+// the index is kept between these markers, leaving the rest of the README
+// alone
+const (
+	indexBegin string = "<!-- tinycode index begin -->"
+	indexEnd          = "<!-- tinycode index end -->"
+)
+
+type row struct {
+	title      string
+	difficulty string
+	lang       string
+	provider   string
+	path       string
+	time       time.Time
+}
+
+// rows returns the solutions committed with their trailers, one per problem
+// and language
+func rows(commits []team.Commit) map[string]row {
+	output := map[string]row{}
+
+	// oldest first, so that later commits override earlier ones
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		problem := commit.Trailers[ProblemTrailer]
+		if commit.Verdict != team.Accepted || problem == "" {
+			continue
+		}
+
+		key := problem + "/" + commit.Trailers[LangTrailer]
+		solved := commit.Time
+		if previous, ok := output[key]; ok {
+			solved = previous.time
+		}
+
+		output[key] = row{
+			title:      commit.Trailers[TitleTrailer],
+			difficulty: commit.Trailers[DifficultyTrailer],
+			lang:       commit.Trailers[LangTrailer],
+			provider:   strings.SplitN(problem, "/", 2)[0],
+			path:       commit.Trailers[PathTrailer],
+			time:       solved,
+		}
+	}
+
+	return output
+}
+
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "[", "\\[", "]", "\\]").Replace(s)
+}
+
+func renderIndex(rows map[string]row) string {
+	var sorted []row
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].time.Equal(sorted[j].time) {
+			return sorted[i].time.Before(sorted[j].time)
+		}
+		return sorted[i].path < sorted[j].path
+	})
+
+	var buf strings.Builder
+	buf.WriteString(indexBegin)
+	buf.WriteString("\n| # | Problem | Difficulty | Language | Provider | Solved |\n")
+	buf.WriteString("|--:|---------|------------|----------|----------|--------|\n")
+	for i, r := range sorted {
+		title := escapeCell(r.title)
+		if r.path != "" {
+			title = fmt.Sprintf("[%s](%s)", title, strings.ReplaceAll(r.path, " ", "%20"))
+		}
+		fmt.Fprintf(&buf, "| %d | %s | %s | %s | %s | %s |\n", i+1, title, escapeCell(r.difficulty), r.lang, r.provider, r.time.Format("2006-01-02"))
+	}
+	buf.WriteString(indexEnd)
+	return buf.String()
+}
+
+// UpdateReadme rewrites the index of solved problems in the README at the root
+// of the repository, adding solution, at path relative to the root, to the
+// ones already committed. It returns the path of the README.
+func UpdateReadme(root string, solution Solution, path string) (string, error) {
+	var commits []team.Commit
+
+	// a repository without commits has none to index
+	if _, err := team.Git(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		if commits, err = team.Log(root); err != nil {
+			return "", err
+		}
+	}
+
+	// the solution is indexed as it is about to be committed
+	current := team.Commit{Time: time.Now(), Trailers: map[string]string{}, Verdict: team.Accepted}
+	for _, trailer := range solution.trailers(path) {
+		current.Trailers[trailer[0]] = trailer[1]
+	}
+	index := renderIndex(rows(append([]team.Commit{current}, commits...)))
+
+	readme := filepath.Join(root, ReadmeFilename)
+	data, err := os.ReadFile(readme)
+	if errors.Is(err, os.ErrNotExist) {
+		data = []byte("# Solutions\n")
+	} else if err != nil {
+		return "", err
+	}
+
+	content := string(data)
+	begin := strings.Index(content, indexBegin)
+	end := strings.Index(content, indexEnd)
+	if begin >= 0 && end > begin {
+		content = content[:begin] + index + content[end+len(indexEnd):]
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n" + index + "\n"
+	}
+
+	return readme, os.WriteFile(readme, []byte(content), 0644)
+}
@@ -3,8 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"github.com/brokad/tinycode/history"
 	"github.com/brokad/tinycode/provider"
-	"github.com/iancoleman/strcase"
+	"github.com/brokad/tinycode/workspace"
 	"github.com/skratchdot/open-golang/open"
 	"github.com/spf13/cobra"
 	"log"
@@ -18,6 +19,9 @@ import (
 var difficultyStr string
 var statusStr string
 var tagsStr string
+var categoryStr string
+var skillsStr string
+var doIncludePremium bool
 var trackStr string
 var doOpen bool
 var doSubmit bool
@@ -39,50 +43,231 @@ func toFileIfNotExists(path string, content string) error {
 		}
 		output.Close()
 	}
-	fmt.Fprintf(os.Stdout, "%s\n", path)
+	if isTextOutput() {
+		fmt.Fprintf(os.Stdout, "%s\n", path)
+	}
 	return nil
 }
 
-var checkoutCmd = &cobra.Command{
-	Use:     "checkout [--problem PROBLEM | --id ID] [-d DIFFICULTY] [-t TAGS] [-l LANG] [--track TRACK] [--contest CONTEST] [--open | --submit] PATH",
-	Short:   "checkout a problem locally",
-	Args:    cobra.MaximumNArgs(1),
-	Example: `  tinycode checkout -d easy -l rust ./`,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if difficultyStr != "" {
-			if err := filters.AddFilter("difficulty", difficultyStr); err != nil {
-				return err
-			}
+// addSearchFilters adds the flags narrowing down a search of problems to the
+// filters
+func addSearchFilters() error {
+	if difficultyStr != "" {
+		if err := filters.AddFilter("difficulty", difficultyStr); err != nil {
+			return err
 		}
+	}
 
-		if statusStr != "" {
-			if err := filters.AddFilter("status", statusStr); err != nil {
-				return err
-			}
+	if statusStr != "" {
+		if err := filters.AddFilter("status", statusStr); err != nil {
+			return err
 		}
+	}
 
-		if tagsStr != "" {
-			if err := filters.AddFilter("tags", tagsStr); err != nil {
-				return err
-			}
+	if tagsStr != "" {
+		if err := filters.AddFilter("tags", tagsStr); err != nil {
+			return err
+		}
+	}
+
+	if categoryStr != "" {
+		if err := filters.AddFilter("category", categoryStr); err != nil {
+			return err
 		}
+	}
 
-		if trackStr != "" {
-			if err := filters.AddFilter("track", trackStr); err != nil {
-				return err
-			}
+	if doIncludePremium {
+		if err := filters.AddFilter("premium", "include"); err != nil {
+			return err
+		}
+	}
+
+	if skillsStr != "" {
+		if err := filters.AddFilter("skills", skillsStr); err != nil {
+			return err
 		}
+	}
+
+	if trackStr != "" {
+		if err := filters.AddFilter("track", trackStr); err != nil {
+			return err
+		}
+	}
+
+	if playlistStr != "" {
+		if err := filters.AddFilter("playlist", playlistStr); err != nil {
+			return err
+		}
+	}
+
+	if sectionStr != "" {
+		if err := filters.AddFilter("section", sectionStr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type checkoutDocument struct {
+	Challenge challengeDocument `json:"challenge"`
+	Lang      string            `json:"lang"`
+	Path      string            `json:"path,omitempty"`
+	Files     []string          `json:"files,omitempty"`
+	Code      string            `json:"code,omitempty"` // only if not written to a path
+	Submit    *submitDocument   `json:"submit,omitempty"` // with --submit
+}
+
+// problemVars are the values of the placeholders of the workspace layout
+func problemVars(identity provider.Filters, details provider.ChallengeDetails, lang provider.Lang) map[string]string {
+	return map[string]string{
+		"provider":   backend,
+		"lang":       lang.String(),
+		"difficulty": details.Difficulty,
+		"slug":       identity.GetFilterOrDefault("slug"),
+		"id":         identity.GetFilterOrDefault("id"),
+		"contest":    identity.GetFilterOrDefault("contest"),
+	}
+}
+
+// resolveChallenge finds a challenge matching the filters, or recommends one
+// with --recommend, unless they already point to one
+func resolveChallenge() error {
+	if _, err := filters.GetFilter("slug"); err != nil && doRecommend {
+		log.Printf("no problem-slug provided, recommending one")
+
+		candidates, err := recommendChallenges()
+		if err != nil {
+			return err
+		}
+		filters.Update(&candidates[0].Summary.Filters)
+	} else if err != nil {
+		log.Printf("no problem-slug provided, finding the next one")
+
+		newFilters, err := client.FindNextChallenge(filters)
+
+		if err != nil {
+			return err
+		} else {
+			filters.Update(&newFilters)
+		}
+	}
+
+	return nil
+}
+
+// checkoutChallenge writes the challenge the filters point to at srcStr, a
+// file or a directory, which it then updates to the path of the solution. The
+// paths of the files which come with the challenge are returned as well. If
+// srcStr is empty, nothing is written and the code is in the document instead.
+func checkoutChallenge() (*checkoutDocument, []string, error) {
+	questionData, err := client.GetChallenge(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lang *provider.Lang
+	if langStr == "" {
+		return nil, nil, fmt.Errorf("a --lang must be provided (e.g. rust)")
+	} else {
+		if lang, err = provider.ParseLang(langStr); err != nil {
+			return nil, nil, err
+		}
+	}
 
-		if _, err := filters.GetFilter("slug"); err != nil {
-			log.Printf("no problem-slug provided, finding the next one")
+	var buf strings.Builder
+	if err := provider.EncodeChallenge(backend, *lang, filters, questionData, &buf); err != nil {
+		return nil, nil, err
+	}
+	questionStr := buf.String()
+
+	questionIdentity := questionData.Identify()
+
+	document := checkoutDocument{
+		Challenge: newChallengeDocument(questionIdentity, questionData.Details()),
+		Lang:      lang.String(),
+	}
+
+	if srcStr == "" {
+		document.Code = questionStr
+		return &document, nil, nil
+	}
+
+	var projectDir string
+
+	stat, err := os.Stat(srcStr)
+	if err == nil && stat.Mode().IsDir() {
+		questionSlug, err := questionIdentity.GetFilter("slug")
+		if err != nil {
+			return nil, nil, err
+		}
 
-			newFilters, err := client.FindNextChallenge(filters)
+		if ws != nil {
+			if projectDir, err = ws.ProblemDir(problemVars(questionIdentity, questionData.Details(), *lang)); err != nil {
+				return nil, nil, err
+			}
 
+			project, err := workspace.Scaffold(*lang, questionSlug, ws.Config.JavaBuild, questionStr)
 			if err != nil {
-				return err
-			} else {
-				filters.Update(&newFilters)
+				return nil, nil, err
+			}
+
+			for name, content := range project.Files {
+				filepath := path.Join(projectDir, name)
+				if err := os.MkdirAll(path.Dir(filepath), os.ModePerm); err != nil {
+					return nil, nil, err
+				}
+				toFileIfNotExists(filepath, content)
+				document.Files = append(document.Files, filepath)
+			}
+
+			srcStr = path.Join(projectDir, project.Source)
+			if err := os.MkdirAll(path.Dir(srcStr), os.ModePerm); err != nil {
+				return nil, nil, err
 			}
+		} else {
+			srcStr = path.Join(srcStr, workspace.Filename(*lang, questionSlug))
+		}
+	}
+
+	toFileIfNotExists(srcStr, questionStr)
+
+	if projectDir == "" {
+		projectDir = path.Dir(srcStr)
+	}
+
+	files, err := questionData.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	for name, content := range files {
+		filepath := path.Join(projectDir, name)
+		toFileIfNotExists(filepath, content)
+		paths = append(paths, filepath)
+	}
+
+	document.Path = srcStr
+	document.Files = append(document.Files, paths...)
+
+	recordHistory(history.NewEntry(history.Checkout, backend, questionIdentity, questionData.Details(), *lang))
+
+	return &document, paths, nil
+}
+
+var checkoutCmd = &cobra.Command{
+	Use:     "checkout [--problem PROBLEM | --id ID] [-d DIFFICULTY] [-t TAGS] [--category CATEGORY] [-l LANG] [--track TRACK] [--skills SKILLS] [--playlist PLAYLIST [--section SECTION]] [--pick first|random] [--skip SLUGS] [--contest CONTEST] [--open | --submit] PATH",
+	Short:   "checkout a problem locally",
+	Args:    cobra.MaximumNArgs(1),
+	Example: `  tinycode checkout -d easy -l rust ./`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := addSearchFilters(); err != nil {
+			return err
+		}
+
+		if err := resolveChallenge(); err != nil {
+			return err
 		}
 
 		if doSubmit {
@@ -100,99 +285,63 @@ var checkoutCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		questionData, err := client.GetChallenge(filters)
+		document, paths, err := checkoutChallenge()
 		if err != nil {
 			return err
 		}
 
-		var lang *provider.Lang
-		if langStr == "" {
-			return fmt.Errorf("a --lang must be provided (e.g. rust)")
-		} else {
-			if lang, err = provider.ParseLang(langStr); err != nil {
+		if isTextOutput() && srcStr == "" {
+			if _, err := fmt.Fprintf(os.Stdout, "%s", document.Code); err != nil {
 				return err
 			}
 		}
 
-		var buf strings.Builder
-		if err := provider.EncodeChallenge(backend, *lang, filters, questionData, &buf); err != nil {
-			return err
-		}
-		questionStr := buf.String()
-
-		questionIdentity := questionData.Identify()
-
-		if srcStr == "" {
-			if _, err := fmt.Fprintf(os.Stdout, "%s", questionStr); err != nil {
+		if srcStr != "" && doOpen {
+			editor := os.Getenv("EDITOR")
+			if editor == "" {
+				log.Fatal("no $EDITOR set, try `export EDITOR=emacs`")
+			}
+			editorCmdArgs := append(strings.Split(editor, " "), srcStr)
+			editorCmd := exec.Command(editorCmdArgs[0], editorCmdArgs[1:]...)
+			editorCmd.Stdout = os.Stdout
+			editorCmd.Stderr = os.Stderr
+			editorCmd.Stdin = os.Stdin
+			if err := editorCmd.Start(); err != nil {
 				return err
 			}
-		} else {
-			stat, err := os.Stat(srcStr)
-			if err == nil && stat.Mode().IsDir() {
-				questionSlug, err := questionIdentity.GetFilter("slug")
-				if err != nil {
-					return err
-				}
 
-				ext := lang.Ext()
-
-				var filename string
-				switch lang.String() {
-				case provider.Swift, provider.Java:
-					filename = strcase.ToCamel(questionSlug)
-				case provider.Rust:
-					filename = strings.ReplaceAll(questionSlug, "-", "_")
-				default:
-					filename = questionSlug
-				}
-
-				srcStr = path.Join(srcStr, fmt.Sprintf("%s.%s", filename, ext))
+			for _, path := range paths {
+				open.Start(path)
 			}
 
-			toFileIfNotExists(srcStr, questionStr)
-
-			srcDir := path.Dir(srcStr)
-			files, err := questionData.Files()
-			if err != nil {
+			if err := editorCmd.Wait(); err != nil {
 				return err
 			}
+		}
 
-			var paths []string
-			for name, content := range files {
-				filepath := path.Join(srcDir, name)
-				toFileIfNotExists(filepath, content)
-				paths = append(paths, filepath)
+		// the verdict is part of the checkout document, so that there is a
+		// single one to parse
+		code := ExitOk
+		var submitReport provider.SubmissionReport
+		if srcStr != "" && doSubmit {
+			if submitReport, document.Submit, err = submitSolution(srcStr); err != nil {
+				return err
 			}
+			code = exitCode(submitReport.Class())
+		}
 
-			if doOpen {
-				editor := os.Getenv("EDITOR")
-				if editor == "" {
-					log.Fatal("no $EDITOR set, try `export EDITOR=emacs`")
-				}
-				editorCmdArgs := append(strings.Split(editor, " "), srcStr)
-				editorCmd := exec.Command(editorCmdArgs[0], editorCmdArgs[1:]...)
-				editorCmd.Stdout = os.Stdout
-				editorCmd.Stderr = os.Stderr
-				editorCmd.Stdin = os.Stdin
-				if err := editorCmd.Start(); err != nil {
-					return err
-				}
-
-				for _, path := range paths {
-					open.Start(path)
-				}
-
-				if err := editorCmd.Wait(); err != nil {
-					return err
-				}
-
-				if doSubmit {
-					rootCmd.SetArgs([]string{"submit", srcStr})
-					return rootCmd.Execute()
-				}
+		if !isTextOutput() {
+			if err := emit(document); err != nil {
+				return err
 			}
+		} else if submitReport != nil {
+			printSubmitReport(submitReport, *document.Submit)
 		}
 
-		return nil
+		if submitReport != nil {
+			gradeReview()
+		}
+
+		return exitWith(code)
 	},
 }
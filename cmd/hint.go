@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+)
+
+type hintDocument struct {
+	Challenge challengeDocument `json:"challenge"`
+	Index     int               `json:"index"` // starting from 1
+	Count     int               `json:"count"`
+	Hint      string            `json:"hint"`
+}
+
+var hintCmd = &cobra.Command{
+	Use:   "hint PATH [N]",
+	Short: "reveal the N-th hint of a checked out problem (the first one by default)",
+	Long: `Problems only mention how many hints they have when checked out, so that
+they can be revealed one at a time as needed.`,
+	Example: `  tinycode hint two-sum.rs 2`,
+	Args:    cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index := 1
+		if len(args) == 2 {
+			var err error
+			if index, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("not a hint number: %s", args[1])
+			}
+		}
+
+		challenge, err := client.GetChallenge(filters)
+		if err != nil {
+			return err
+		}
+
+		var hints []string
+		if annotated, ok := challenge.(provider.Annotated); ok {
+			hints = annotated.Annotations().Hints
+		}
+
+		if len(hints) == 0 {
+			return fmt.Errorf("%s has no hints", challenge.Details().Title)
+		} else if index < 1 || index > len(hints) {
+			return fmt.Errorf("no hint %d: %s has %d", index, challenge.Details().Title, len(hints))
+		}
+
+		hint := hints[index-1]
+
+		if !isTextOutput() {
+			return emit(hintDocument{
+				Challenge: newChallengeDocument(challenge.Identify(), challenge.Details()),
+				Index:     index,
+				Count:     len(hints),
+				Hint:      hint,
+			})
+		}
+
+		header := color.New(color.Bold)
+		header.Fprintf(os.Stdout, "Hint %d/%d", index, len(hints))
+		fmt.Fprintf(os.Stdout, ": %s\n", hint)
+
+		return nil
+	},
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/workspace"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+var layoutStr string
+var javaBuildStr string
+
+type initDocument struct {
+	Workspace string `json:"workspace"`
+	Config    string `json:"config"`
+}
+
+var initCmd = &cobra.Command{
+	Use:     "init [-p PROVIDER] [-l LANG] [--layout LAYOUT] [--java-build gradle | --java-build maven] [PATH]",
+	Short:   "initialize a workspace for checked out problems",
+	Example: `  tinycode init -p leetcode -l rust ./problems`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := srcStr
+		if root == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			root = wd
+		}
+
+		if langStr != "" {
+			if _, err := provider.ParseLang(langStr); err != nil {
+				return err
+			}
+		}
+
+		if javaBuildStr != workspace.Gradle && javaBuildStr != workspace.Maven {
+			return fmt.Errorf("unknown java-build: %s (must be gradle or maven)", javaBuildStr)
+		}
+
+		config := workspace.NewConfig()
+		config.Provider = backend
+		config.Lang = langStr
+		config.Layout = layoutStr
+		config.JavaBuild = javaBuildStr
+
+		if _, err := workspace.Init(root, config); err != nil {
+			return err
+		}
+
+		configFile := filepath.Join(root, workspace.ConfigFilename)
+
+		if !isTextOutput() {
+			return emit(initDocument{
+				Workspace: root,
+				Config:    configFile,
+			})
+		}
+
+		fmt.Fprintf(os.Stdout, "%s\n", configFile)
+
+		return nil
+	},
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+var offset uint64
+var limit uint64
+
+type listDocument struct {
+	Challenges []challengeDocument `json:"challenges"`
+}
+
+func newListDocument(summaries []provider.ChallengeSummary) listDocument {
+	document := listDocument{Challenges: []challengeDocument{}}
+	for _, summary := range summaries {
+		challenge := newChallengeDocument(summary.Filters, summary.Details)
+		challenge.Status = summary.Status
+		document.Challenges = append(document.Challenges, challenge)
+	}
+	return document
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list [-d DIFFICULTY] [--status STATUS] [-t TAGS] [--category CATEGORY] [--track TRACK] [--playlist PLAYLIST [--section SECTION]] [--contest CONTEST] [--offset N] [--limit N]",
+	Short:   "list the problems matching a search",
+	Example: `  tinycode list -p leetcode -d easy --status todo`,
+	Args:    cobra.ExactArgs(0),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return addSearchFilters()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		summaries, err := client.List(filters, offset, limit)
+		if err != nil {
+			return err
+		}
+
+		document := newListDocument(summaries)
+
+		switch outputStr {
+		case JsonOutput:
+			return emit(document)
+		case NdjsonOutput:
+			for _, challenge := range document.Challenges {
+				if err := emit(challenge); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, summary := range summaries {
+			fmt.Fprintf(
+				writer,
+				"%s\t%s\t%s\t%s\n",
+				summary.Filters.GetFilterOrDefault("slug"),
+				summary.Details.Difficulty,
+				summary.Status,
+				summary.Details.Title,
+			)
+		}
+		return writer.Flush()
+	},
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"github.com/brokad/tinycode/hackerrank"
+	"github.com/brokad/tinycode/leetcode"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
@@ -12,8 +13,13 @@ import (
 var csrf string
 var session string
 
+type loginDocument struct {
+	Provider string `json:"provider"`
+	Config   string `json:"config"`
+}
+
 var loginCmd = &cobra.Command{
-	Use:     "login [-c CSRF] [-s TOKEN] [-p hackerrank | -p leetcode]",
+	Use:     "login [-c CSRF] [-s TOKEN] [-p hackerrank | -p leetcode | -p leetcode-cn]",
 	Short:   "configure authentication for problem set providers",
 	Example: `  tinycode login -p hackerrank`,
 	Args:    cobra.ExactArgs(0),
@@ -45,12 +51,22 @@ var loginCmd = &cobra.Command{
 				csrf = newCsrf
 				session = newSession
 			}
+		case *leetcode.Client:
+			// leetcode.cn gives a csrf token to anyone, so only the session
+			// has to be copied from the browser
+			if csrf == "" && c.Site().China {
+				newCsrf, err := c.FetchCsrfToken()
+				if err != nil {
+					return err
+				}
+				csrf = newCsrf
+			}
 		}
 
 		var mutate = false
 
 		if csrf == "" {
-			fmt.Print("csrf: ")
+			fmt.Fprint(os.Stderr, "csrf: ")
 			if _, err := fmt.Scanln(&csrf); err != nil {
 				return err
 			}
@@ -62,7 +78,7 @@ var loginCmd = &cobra.Command{
 		}
 
 		if session == "" {
-			fmt.Print("session token: ")
+			fmt.Fprint(os.Stderr, "session token: ")
 			if _, err := fmt.Scanln(&session); err != nil {
 				return err
 			}
@@ -81,6 +97,13 @@ var loginCmd = &cobra.Command{
 			return fmt.Errorf("no csrf or session token: not doing anything")
 		}
 
+		if !isTextOutput() {
+			return emit(loginDocument{
+				Provider: backend,
+				Config:   viper.ConfigFileUsed(),
+			})
+		}
+
 		return nil
 	},
 }
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/recommend"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"log"
+	"strings"
+	"time"
+)
+
+// Flags and parameters
+var doRecommend bool
+var doExplain bool
+var recommendCount int
+
+// recommendPool is how many problems matching the filters are considered for
+// a recommendation
+const recommendPool = 100
+
+// recommendTags is how many of the weakest tags have problems of their own
+// considered as well, on providers which filter problems by tag
+const recommendTags = 3
+
+type recommendationDocument struct {
+	Challenge challengeDocument  `json:"challenge"`
+	Status    string             `json:"status,omitempty"`
+	Score     float64            `json:"score"`
+	Factors   []recommend.Factor `json:"factors"`
+}
+
+// tagSlugs gathers the slugs of tags by name from what the provider told of
+// them, in the history, the profile or the pool of problems
+func tagSlugs(entries []history.Entry, profile *provider.Profile, pool []provider.ChallengeSummary) map[string]string {
+	output := map[string]string{}
+	for _, entry := range entries {
+		for name, slug := range entry.TagSlugs {
+			output[name] = slug
+		}
+	}
+	if profile != nil {
+		for name, slug := range profile.TagSlugs {
+			output[name] = slug
+		}
+	}
+	for _, summary := range pool {
+		for name, slug := range summary.Details.TagSlugs {
+			output[name] = slug
+		}
+	}
+	return output
+}
+
+// recommendChallenges ranks the problems matching the filters which are not
+// solved yet, the best ones to work on next first
+func recommendChallenges() ([]recommend.Candidate, error) {
+	entries, err := history.Read(history.Path(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var profile *provider.Profile
+	if profiled, ok := client.(provider.Profiled); ok {
+		if profile, err = profiled.Profile(); err != nil {
+			log.Printf("could not fetch the profile, recommending from the history only: %s", err)
+		}
+	}
+
+	model := recommend.NewModel(backend, entries, profile, time.Now())
+
+	pool, err := client.List(filters, 0, recommendPool)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := filters.GetFilter("tags"); err != nil && (backend == LeetCode || backend == LeetCodeCn) {
+		slugs := tagSlugs(entries, profile, pool)
+		listed := 0
+		for _, tag := range model.WeakestTags() {
+			if listed == recommendTags {
+				break
+			}
+
+			slug, ok := slugs[tag]
+			if !ok {
+				log.Printf("slug of tag %s unknown, not listing problems tagged with it", tag)
+				continue
+			}
+
+			tagFilters := provider.Filters{}
+			tagFilters.Update(&filters)
+			if err := tagFilters.AddFilter("tags", slug); err != nil {
+				continue
+			}
+			listed++
+
+			summaries, err := client.List(tagFilters, 0, recommendPool/recommendTags)
+			if err != nil {
+				log.Printf("could not list problems tagged %s: %s", tag, err)
+				continue
+			}
+			pool = append(pool, summaries...)
+		}
+	}
+
+	candidates := model.Rank(pool)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("could not find a problem to recommend, try removing conditions")
+	}
+	return candidates, nil
+}
+
+func newRecommendationDocument(candidate recommend.Candidate) recommendationDocument {
+	return recommendationDocument{
+		Challenge: newChallengeDocument(candidate.Summary.Filters, candidate.Summary.Details),
+		Status:    candidate.Summary.Status,
+		Score:     candidate.Score,
+		Factors:   candidate.Factors,
+	}
+}
+
+var nextCmd = &cobra.Command{
+	Use:     "next [-d DIFFICULTY] [-t TAGS] [--category CATEGORY] [--track TRACK] [--contest CONTEST] [-n COUNT] [--explain]",
+	Short:   "recommend problems to work on next, targeting your weak areas",
+	Example: `  tinycode next -p leetcode --explain`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := addSearchFilters(); err != nil {
+			return err
+		}
+
+		candidates, err := recommendChallenges()
+		if err != nil {
+			return err
+		}
+
+		if recommendCount > 0 && len(candidates) > recommendCount {
+			candidates = candidates[:recommendCount]
+		}
+
+		if !isTextOutput() {
+			documents := []recommendationDocument{}
+			for _, candidate := range candidates {
+				documents = append(documents, newRecommendationDocument(candidate))
+			}
+			return emit(documents)
+		}
+
+		bold := color.New(color.Bold)
+		faint := color.New(color.Faint)
+		for i, candidate := range candidates {
+			details := candidate.Summary.Details
+			slug := candidate.Summary.Filters.GetFilterOrDefault("slug")
+			fmt.Printf("%s %s (%s, %s) %s\n", bold.Sprint(details.Title), slug, strings.ToLower(details.Difficulty), strings.Join(details.Tags, ", "), faint.Sprintf("%.2f", candidate.Score))
+
+			if doExplain {
+				for _, factor := range candidate.Factors {
+					fmt.Printf("  %-10s %.2f  %s\n", factor.Name, factor.Score, factor.Detail)
+				}
+				if i+1 < len(candidates) {
+					fmt.Println()
+				}
+			}
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/sandbox"
+	"os"
+)
+
+const (
+	TextOutput   string = "text"
+	JsonOutput          = "json"
+	NdjsonOutput        = "ndjson"
+)
+
+// Exit codes, stable across versions so that scripts can rely on them
+const (
+	ExitOk                  = 0
+	ExitError               = 1 // tinycode itself failed, e.g. bad flags or network errors
+	ExitWrongAnswer         = 10
+	ExitCompileError        = 11
+	ExitRuntimeError        = 12
+	ExitTimeLimitExceeded   = 13
+	ExitMemoryLimitExceeded = 14
+	ExitOutputLimitExceeded = 15
+	ExitOtherError          = 19
+)
+
+var outputStr string
+
+func validateOutput(s string) error {
+	switch s {
+	case TextOutput, JsonOutput, NdjsonOutput:
+		return nil
+	default:
+		return fmt.Errorf("unknown output: %s (must be text, json or ndjson)", s)
+	}
+}
+
+// isTextOutput is true if output is meant for humans, in which case commands
+// print prose (and colors) rather than documents
+func isTextOutput() bool {
+	return outputStr == TextOutput
+}
+
+// emit writes document to stdout, indented for json and on a single line for
+// ndjson
+func emit(document interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	if outputStr == JsonOutput {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(document)
+}
+
+func exitCode(class provider.VerdictClass) int {
+	switch class {
+	case provider.Accepted:
+		return ExitOk
+	case provider.WrongAnswer:
+		return ExitWrongAnswer
+	case provider.CompileError:
+		return ExitCompileError
+	case provider.RuntimeError:
+		return ExitRuntimeError
+	case provider.TimeLimitExceeded:
+		return ExitTimeLimitExceeded
+	case provider.MemoryLimitExceeded:
+		return ExitMemoryLimitExceeded
+	case provider.OutputLimitExceeded:
+		return ExitOutputLimitExceeded
+	default:
+		return ExitOtherError
+	}
+}
+
+// exitCodeError ends a command which reported its outcome itself, making
+// Execute exit with code once the deferred cleanups of the command have run
+type exitCodeError struct {
+	code int
+}
+
+func (err *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", err.code)
+}
+
+// exitWith is the error of a command which should exit with code
+func exitWith(code int) error {
+	if code == ExitOk {
+		return nil
+	}
+	return &exitCodeError{code: code}
+}
+
+// localClass sorts the verdict of a local run, which is known to have produced
+// the expected output or not
+func localClass(verdict *sandbox.Verdict, sameOutput bool) provider.VerdictClass {
+	switch verdict.Status {
+	case sandbox.Ok:
+		if sameOutput {
+			return provider.Accepted
+		}
+		return provider.WrongAnswer
+	case sandbox.TimeLimitExceeded:
+		return provider.TimeLimitExceeded
+	case sandbox.MemoryLimitExceeded:
+		return provider.MemoryLimitExceeded
+	case sandbox.OutputLimitExceeded:
+		return provider.OutputLimitExceeded
+	default:
+		return provider.RuntimeError
+	}
+}
+
+type challengeDocument struct {
+	Provider   string            `json:"provider"`
+	Identity   map[string]string `json:"identity"`
+	Title      string            `json:"title,omitempty"`
+	Difficulty string            `json:"difficulty,omitempty"`
+	Status     string            `json:"status,omitempty"`
+}
+
+func newChallengeDocument(identity provider.Filters, details provider.ChallengeDetails) challengeDocument {
+	return challengeDocument{
+		Provider:   backend,
+		Identity:   identity.Map(),
+		Title:      details.Title,
+		Difficulty: details.Difficulty,
+	}
+}
+
+type errorDocument struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/plan"
+	"github.com/brokad/tinycode/provider"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+)
+
+// Flags and parameters
+var favoritesStr string
+var studyPlanStr string
+
+type planEntryDocument struct {
+	Provider string            `json:"provider"`
+	Identity map[string]string `json:"identity"`
+	Title    string            `json:"title,omitempty"`
+	Notes    string            `json:"notes,omitempty"`
+	Solved   bool              `json:"solved"`
+	problem  string
+}
+
+type planDocument struct {
+	Name     string              `json:"name"`
+	Path     string              `json:"path"`
+	Solved   int                 `json:"solved"`
+	Total    int                 `json:"total"`
+	Problems []planEntryDocument `json:"problems"`
+}
+
+type planRunDocument struct {
+	Plan     planDocument      `json:"plan"`
+	Checkout *checkoutDocument `json:"checkout,omitempty"` // none once every problem is solved
+}
+
+// entryFilters identifies the problem of an entry, as checkout expects
+func entryFilters(entry plan.Entry) (provider.Filters, error) {
+	identity := provider.Filters{}
+	if err := identity.AddFilter("slug", entry.Slug); err != nil {
+		return identity, err
+	}
+	if entry.Id != "" {
+		if err := identity.AddFilter("id", entry.Id); err != nil {
+			return identity, err
+		}
+	}
+	if entry.Contest != "" {
+		if err := identity.AddFilter("contest", entry.Contest); err != nil {
+			return identity, err
+		}
+	} else if entry.Provider == HackerRank {
+		_ = identity.AddFilter("contest", "master")
+	}
+	return identity, nil
+}
+
+// solvedPageSize is the number of problems fetched at once when listing the
+// problems solved on a provider
+const solvedPageSize = 100
+
+// solvedOnProvider adds to output the problems the provider reports as solved,
+// which it only does on LeetCode
+func solvedOnProvider(backend string, output map[string]bool) error {
+	if backend != LeetCode && backend != LeetCodeCn {
+		return nil
+	}
+
+	client, err := newClient(backend)
+	if err != nil {
+		return err
+	}
+	if err := authenticate(client, backend); err != nil {
+		return err
+	}
+
+	// paid-only problems count as well, if they were solved
+	filters := provider.Filters{}
+	if err := filters.AddFilter("status", provider.Solved); err != nil {
+		return err
+	}
+	if err := filters.AddFilter("premium", "include"); err != nil {
+		return err
+	}
+
+	for offset := uint64(0); ; offset += solvedPageSize {
+		summaries, err := client.List(filters, offset, solvedPageSize)
+		if err != nil {
+			return err
+		}
+		for _, summary := range summaries {
+			if summary.Status == provider.Solved {
+				output[problemOf(backend, summary.Filters)] = true
+			}
+		}
+		if len(summaries) < solvedPageSize {
+			return nil
+		}
+	}
+}
+
+// solvedProblems returns the problems with an accepted submission in the
+// local history, or reported as solved by the providers of the lists
+func solvedProblems(lists ...*plan.List) (map[string]bool, error) {
+	entries, err := history.Read(history.Path(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	output := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsAccepted() {
+			output[entry.Problem()] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, entry := range list.Problems {
+			if seen[entry.Provider] {
+				continue
+			}
+			seen[entry.Provider] = true
+
+			if err := solvedOnProvider(entry.Provider, output); err != nil {
+				log.Printf("could not list the problems solved on %s, using the local history only: %s", entry.Provider, err)
+			}
+		}
+	}
+	return output, nil
+}
+
+func newPlanDocument(path string, list *plan.List, solved map[string]bool) (planDocument, error) {
+	document := planDocument{
+		Name:     list.Name,
+		Path:     path,
+		Total:    len(list.Problems),
+		Problems: []planEntryDocument{},
+	}
+
+	for _, entry := range list.Problems {
+		identity, err := entryFilters(entry)
+		if err != nil {
+			return document, err
+		}
+
+		entryDocument := planEntryDocument{
+			Provider: entry.Provider,
+			Identity: identity.Map(),
+			Title:    entry.Title,
+			Notes:    entry.Notes,
+			problem:  problemOf(entry.Provider, identity),
+		}
+		entryDocument.Solved = solved[entryDocument.problem]
+		if entryDocument.Solved {
+			document.Solved++
+		}
+		document.Problems = append(document.Problems, entryDocument)
+	}
+
+	return document, nil
+}
+
+// nextEntry returns the first entry of the document which is not solved yet
+func (document *planDocument) nextEntry() int {
+	for i, entry := range document.Problems {
+		if !entry.Solved {
+			return i
+		}
+	}
+	return -1
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "work through lists of problems kept as local files",
+}
+
+var planRunCmd = &cobra.Command{
+	Use:     "run [-l LANG] LIST [DIR]",
+	Short:   "check out the next problem of a list which is not solved yet",
+	Example: `  tinycode plan run graphs-week.toml`,
+	Args:    cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := plan.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		solved, err := solvedProblems(list)
+		if err != nil {
+			return err
+		}
+
+		document, err := newPlanDocument(args[0], list, solved)
+		if err != nil {
+			return err
+		}
+
+		next := document.nextEntry()
+		if next < 0 {
+			if !isTextOutput() {
+				return emit(planRunDocument{Plan: document})
+			}
+			fmt.Fprintf(os.Stderr, "tinycode: all %d problems of %s are solved\n", document.Total, list.Name)
+			return nil
+		}
+		entry := list.Problems[next]
+
+		// the provider of the entry may not be the one set up before running
+		// the command
+		backend = entry.Provider
+		if client, err = newClient(backend); err != nil {
+			return err
+		}
+		if err := authenticate(client, backend); err != nil {
+			return err
+		}
+
+		if filters, err = entryFilters(entry); err != nil {
+			return err
+		}
+
+		srcStr = "."
+		if len(args) > 1 {
+			srcStr = args[1]
+		}
+		ws = findWorkspace(srcStr)
+
+		if !cmd.Flags().Changed("lang") {
+			langStr = entry.Lang
+		}
+		inferLang()
+
+		checkout, _, err := checkoutChallenge()
+		if err != nil {
+			return err
+		}
+
+		if !isTextOutput() {
+			return emit(planRunDocument{Plan: document, Checkout: checkout})
+		}
+
+		fmt.Fprintf(os.Stderr, "tinycode: problem %d of %d in %s (%d solved)\n", next+1, document.Total, list.Name, document.Solved)
+		if entry.Notes != "" {
+			fmt.Fprintf(os.Stderr, "tinycode: notes: %s\n", entry.Notes)
+		}
+		return nil
+	},
+}
+
+var planStatusCmd = &cobra.Command{
+	Use:     "status LIST...",
+	Short:   "report on the progress through lists of problems",
+	Example: `  tinycode plan status plans/*.toml`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var lists []*plan.List
+		for _, path := range args {
+			list, err := plan.Load(path)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, list)
+		}
+
+		solved, err := solvedProblems(lists...)
+		if err != nil {
+			return err
+		}
+
+		var documents []planDocument
+		for i, path := range args {
+			document, err := newPlanDocument(path, lists[i], solved)
+			if err != nil {
+				return err
+			}
+			documents = append(documents, document)
+		}
+
+		if !isTextOutput() {
+			return emit(documents)
+		}
+
+		bold := color.New(color.Bold)
+		done := color.New(color.FgGreen)
+
+		// a problem may be in several lists, and is only counted once
+		unique := map[string]bool{}
+		for i, document := range documents {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s %d/%d\n", bold.Sprint(document.Name), document.Solved, document.Total)
+
+			next := document.nextEntry()
+			for j, entry := range document.Problems {
+				unique[entry.problem] = unique[entry.problem] || entry.Solved
+
+				mark := "[ ]"
+				if entry.Solved {
+					mark = done.Sprint("[x]")
+				} else if j == next {
+					mark = bold.Sprint("[>]")
+				}
+
+				title := entry.Title
+				if title == "" {
+					title = entry.Identity["slug"]
+				}
+				fmt.Printf("  %s %s", mark, title)
+				if entry.Notes != "" {
+					fmt.Printf(" (%s)", entry.Notes)
+				}
+				fmt.Println()
+			}
+		}
+
+		if len(documents) > 1 {
+			count := 0
+			for _, isSolved := range unique {
+				if isSolved {
+					count++
+				}
+			}
+			fmt.Printf("\n%s %d/%d distinct problems\n", bold.Sprint("Overall"), count, len(unique))
+		}
+
+		return nil
+	},
+}
+
+var planImportCmd = &cobra.Command{
+	Use:     "import [--favorites NAME | --study-plan SLUG] FILE",
+	Short:   "import a list of favorites or a study plan from the provider (leetcode only)",
+	Example: `  tinycode plan import -p leetcode --study-plan top-interview-150 top-interview-150.toml`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		curated, ok := client.(provider.Curated)
+		if !ok {
+			return fmt.Errorf("%s does not keep lists of problems", backend)
+		}
+
+		var imported *provider.ProblemList
+		if studyPlanStr != "" {
+			var err error
+			if imported, err = curated.StudyPlan(studyPlanStr); err != nil {
+				return err
+			}
+		} else {
+			favorites, err := curated.Favorites()
+			if err != nil {
+				return err
+			}
+
+			for i := range favorites {
+				if favoritesStr != "" && (favorites[i].Name == favoritesStr || favorites[i].Slug == favoritesStr) {
+					imported = &favorites[i]
+				}
+			}
+
+			if imported == nil {
+				var names []string
+				for _, favorites := range favorites {
+					names = append(names, fmt.Sprintf("%q", favorites.Name))
+				}
+				if favoritesStr == "" {
+					return fmt.Errorf("a --favorites list or a --study-plan must be provided, your lists are: %v", names)
+				}
+				return fmt.Errorf("no list of favorites named %q, your lists are: %v", favoritesStr, names)
+			}
+		}
+
+		list := plan.List{
+			Name:     imported.Name,
+			Provider: backend,
+			Lang:     langStr,
+		}
+		for _, summary := range imported.Problems {
+			list.Problems = append(list.Problems, plan.Entry{
+				Provider: backend,
+				Slug:     summary.Filters.GetFilterOrDefault("slug"),
+				Id:       summary.Filters.GetFilterOrDefault("id"),
+				Contest:  summary.Filters.GetFilterOrDefault("contest"),
+				Title:    summary.Details.Title,
+				Lang:     langStr,
+			})
+		}
+
+		if _, err := os.Stat(args[0]); err == nil {
+			return fmt.Errorf("file already exists: %s", args[0])
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := list.Write(f); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "tinycode: imported %d problems from %s into %s\n", len(list.Problems), list.Name, args[0])
+		return nil
+	},
+}
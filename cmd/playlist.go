@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/hackerrank"
+	"github.com/brokad/tinycode/provider"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// Flags and parameters
+var playlistStr string
+var sectionStr string
+
+type sectionDocument struct {
+	Name       string              `json:"name"`
+	Slug       string              `json:"slug"`
+	Solved     int                 `json:"solved"`
+	Total      int                 `json:"total"`
+	Challenges []challengeDocument `json:"challenges"`
+}
+
+type playlistDocument struct {
+	Slug     string            `json:"slug"`
+	Solved   int               `json:"solved"`
+	Total    int               `json:"total"`
+	Sections []sectionDocument `json:"sections"`
+}
+
+func newPlaylistDocument(slug string, sections []hackerrank.Section) playlistDocument {
+	document := playlistDocument{Slug: slug, Sections: []sectionDocument{}}
+	for _, section := range sections {
+		sectionDocument := sectionDocument{
+			Name:       section.Name,
+			Slug:       section.Slug,
+			Solved:     section.Solved(),
+			Total:      len(section.Challenges),
+			Challenges: []challengeDocument{},
+		}
+		for _, challenge := range section.Challenges {
+			summary := challenge.Summarize()
+			challengeDocument := newChallengeDocument(summary.Filters, summary.Details)
+			challengeDocument.Status = summary.Status
+			sectionDocument.Challenges = append(sectionDocument.Challenges, challengeDocument)
+		}
+
+		document.Solved += sectionDocument.Solved
+		document.Total += sectionDocument.Total
+		document.Sections = append(document.Sections, sectionDocument)
+	}
+	return document
+}
+
+var playlistCmd = &cobra.Command{
+	Use:   "playlist [--section SECTION] PLAYLIST",
+	Short: "show the sections of a HackerRank playlist, and the progress through them",
+	Long: `Playlists are lists of challenges to solve in order, such as the Interview
+Preparation Kit (interview-preparation-kit), 30 Days of Code (30-days-of-code)
+or 10 Days of JavaScript (10-days-of-javascript). Check out their next challenge
+with: tinycode checkout --playlist PLAYLIST`,
+	Example:           `  tinycode playlist interview-preparation-kit --section warmup`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completePlaylists,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hrClient, ok := client.(*hackerrank.Client)
+		if !ok {
+			return fmt.Errorf("playlists are only available on hackerrank")
+		}
+
+		sections, err := hrClient.Sections(args[0], sectionStr)
+		if err != nil {
+			return err
+		}
+
+		document := newPlaylistDocument(args[0], sections)
+		if !isTextOutput() {
+			return emit(document)
+		}
+
+		bold := color.New(color.Bold)
+		faint := color.New(color.Faint)
+		done := color.New(color.FgGreen)
+
+		fmt.Printf("%s %d/%d\n", bold.Sprint(document.Slug), document.Solved, document.Total)
+		for _, section := range document.Sections {
+			fmt.Printf("  %s %s %d/%d\n", section.Slug, faint.Sprint(section.Name), section.Solved, section.Total)
+
+			// challenges are only shown for a single section, kits have
+			// hundreds of them
+			if sectionStr == "" {
+				continue
+			}
+			for _, challenge := range section.Challenges {
+				mark := "[ ]"
+				if challenge.Status == provider.Solved {
+					mark = done.Sprint("[x]")
+				}
+				fmt.Printf("    %s %s %s\n", mark, challenge.Identity["slug"], faint.Sprint(strings.ToLower(challenge.Difficulty)))
+			}
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/brokad/tinycode/console"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/review"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flags and parameters
+var doAll bool
+
+type reviewDocument struct {
+	Card     review.Card       `json:"card"`
+	Due      int               `json:"due"` // number of problems due, this one included
+	Checkout *checkoutDocument `json:"checkout"`
+}
+
+// problemOf identifies the problem the filters point to, as history.Entry and
+// review.Card do
+func problemOf(backend string, identity provider.Filters) string {
+	return fmt.Sprintf("%s/%s/%s", backend, identity.GetFilterOrDefault("contest"), identity.GetFilterOrDefault("slug"))
+}
+
+// loadReviews reads the review queue, adding to it the problems solved since
+// it was last read
+func loadReviews() (*review.Queue, error) {
+	queue, err := review.Load(review.Path(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := history.Read(history.Path(configPath))
+	if err != nil {
+		return nil, err
+	}
+	queue.Sync(entries)
+
+	return queue, nil
+}
+
+func saveReviews(queue *review.Queue) error {
+	return queue.Save(review.Path(configPath))
+}
+
+// readGrade asks for the grade of a review on the terminal
+func readGrade(reader *bufio.Reader) (int, error) {
+	for {
+		fmt.Fprintf(os.Stderr, "How well did you recall the solution? 0 (not at all) to %d (perfectly): ", review.MaxGrade)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+
+		grade, err := strconv.Atoi(strings.TrimSpace(line))
+		if err == nil && grade >= 0 && grade <= review.MaxGrade {
+			return grade, nil
+		}
+	}
+}
+
+// gradeReview reschedules the problem the filters point to if it was checked
+// out for review, asking for a grade if possible
+func gradeReview() {
+	queue, err := review.Load(review.Path(configPath))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tinycode: could not read the review queue: %s\n", err)
+		return
+	}
+
+	card := queue.Find(problemOf(backend, filters))
+	if card == nil || !card.Pending {
+		return
+	}
+
+	if !isTextOutput() || srcStr == "" || !console.IsTerminal(os.Stdin) {
+		fmt.Fprintf(os.Stderr, "tinycode: grade this review with `tinycode review grade PATH GRADE`\n")
+		return
+	}
+
+	fmt.Fprintln(os.Stderr)
+	grade, err := readGrade(bufio.NewReader(os.Stdin))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tinycode: could not read a grade: %s\n", err)
+		return
+	}
+
+	if err := card.Grade(grade, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "tinycode: %s\n", err)
+		return
+	}
+
+	if err := saveReviews(queue); err != nil {
+		fmt.Fprintf(os.Stderr, "tinycode: could not save the review queue: %s\n", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "tinycode: next review in %s\n", pluralDays(card.Interval))
+}
+
+func formatDue(card *review.Card, now time.Time) string {
+	days := int(now.Sub(card.Due).Hours() / 24)
+	switch {
+	case card.Pending:
+		return "in review"
+	case !card.IsDue(now):
+		return "in " + pluralDays(int(card.Due.Sub(now).Hours()/24)+1)
+	case days == 0:
+		return "due today"
+	default:
+		return fmt.Sprintf("due for %s", pluralDays(days))
+	}
+}
+
+var reviewCmd = &cobra.Command{
+	Use:     "review [-l LANG] [DIR]",
+	Short:   "check out the solved problem most due for review, without your previous solution",
+	Example: `  tinycode review -p leetcode`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, err := loadReviews()
+		if err != nil {
+			return err
+		}
+
+		due := queue.Due(backend, time.Now())
+		if len(due) == 0 {
+			if err := saveReviews(queue); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "tinycode: nothing to review on %s\n", backend)
+			return nil
+		}
+		card := due[0]
+
+		for name, value := range card.Identity {
+			if err := filters.AddFilter(name, value); err != nil {
+				return err
+			}
+		}
+
+		if !cmd.Flags().Changed("lang") && card.Lang != "" {
+			langStr = card.Lang
+		}
+
+		// a directory of its own, so that a previous solution is never reused
+		dir := os.TempDir()
+		if len(args) != 0 {
+			dir = args[0]
+		}
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		if srcStr, err = os.MkdirTemp(dir, "review-"+card.Identity["slug"]+"-"); err != nil {
+			return err
+		}
+
+		// checkouts in a workspace go to the directory of the problem, where
+		// the previous solution is
+		ws = nil
+
+		document, _, err := checkoutChallenge()
+		if err != nil {
+			return err
+		}
+
+		card.Pending = true
+		if err := saveReviews(queue); err != nil {
+			return err
+		}
+
+		if !isTextOutput() {
+			return emit(reviewDocument{Card: *card, Due: len(due), Checkout: document})
+		}
+
+		fmt.Fprintf(os.Stderr, "tinycode: %d problem(s) due, submit with `tinycode submit %s` to grade this review\n", len(due), srcStr)
+		return nil
+	},
+}
+
+var reviewListCmd = &cobra.Command{
+	Use:   "list [--all]",
+	Short: "list the problems due for review",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, err := loadReviews()
+		if err != nil {
+			return err
+		}
+		if err := saveReviews(queue); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var cards []*review.Card
+		if doAll {
+			for i := range queue.Cards {
+				cards = append(cards, &queue.Cards[i])
+			}
+		} else {
+			cards = queue.Due(backend, now)
+		}
+
+		if !isTextOutput() {
+			document := []review.Card{}
+			for _, card := range cards {
+				document = append(document, *card)
+			}
+			return emit(document)
+		}
+
+		bold := color.New(color.Bold)
+		for _, card := range cards {
+			title := card.Title
+			if title == "" {
+				title = card.Identity["slug"]
+			}
+			details := fmt.Sprintf("ease %.2f", card.Ease)
+			if card.Difficulty != "" {
+				details = strings.ToLower(card.Difficulty) + ", " + details
+			}
+			fmt.Printf("%-16s %s %s (%s)\n", formatDue(card, now), bold.Sprint(title), card.Problem(), details)
+		}
+		return nil
+	},
+}
+
+var reviewGradeCmd = &cobra.Command{
+	Use:     "grade PATH GRADE",
+	Short:   fmt.Sprintf("grade the review of a problem from 0 (not recalled) to %d (perfectly recalled)", review.MaxGrade),
+	Example: `  tinycode review grade /tmp/review-two-sum-123/two-sum.rs 4`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		grade, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid grade: %s", args[1])
+		}
+
+		queue, err := loadReviews()
+		if err != nil {
+			return err
+		}
+
+		card := queue.Find(problemOf(backend, filters))
+		if card == nil {
+			return fmt.Errorf("%s is not in the review queue", problemOf(backend, filters))
+		}
+
+		if err := card.Grade(grade, time.Now()); err != nil {
+			return err
+		}
+
+		if err := saveReviews(queue); err != nil {
+			return err
+		}
+
+		if !isTextOutput() {
+			return emit(card)
+		}
+
+		fmt.Printf("next review of %s in %s\n", card.Problem(), pluralDays(card.Interval))
+		return nil
+	},
+}
+
+var reviewExportCmd = &cobra.Command{
+	Use:     "export [FILE]",
+	Short:   "export the review queue as JSON, to stdout by default",
+	Example: `  tinycode review export reviews.json`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queue, err := loadReviews()
+		if err != nil {
+			return err
+		}
+
+		if len(args) != 0 {
+			return queue.Save(args[0])
+		}
+
+		return queue.Encode(os.Stdout)
+	},
+}
+
+var reviewImportCmd = &cobra.Command{
+	Use:     "import FILE",
+	Short:   "merge a review queue exported as JSON into yours, keeping the most recent reviews",
+	Example: `  tinycode review import reviews.json`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(filepath.Clean(args[0]))
+		if err != nil {
+			return err
+		}
+
+		imported, err := review.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		queue, err := loadReviews()
+		if err != nil {
+			return err
+		}
+
+		changed := queue.Merge(imported)
+		if err := saveReviews(queue); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "tinycode: imported %d card(s) out of %d\n", changed, len(imported.Cards))
+		return nil
+	},
+}
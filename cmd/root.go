@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"github.com/brokad/tinycode/hackerrank"
 	"github.com/brokad/tinycode/leetcode"
 	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/workspace"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"log"
@@ -13,8 +16,11 @@ import (
 	"os"
 	"os/user"
 	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"time"
 )
 
 // Flags and parameters
@@ -26,17 +32,22 @@ var problemSlug string
 var contestSlug string
 var srcStr string
 var doPurchase bool
+var pickStr string
+var skipStr string
 var debug bool
 
 // State variables
 var filters = provider.Filters{}
 var config provider.Config
+var ws *workspace.Workspace
 
 const (
 	HackerRankUrl string = "https://www.hackerrank.com/"
 	HackerRank           = "hackerrank"
 	LeetCodeUrl          = "https://leetcode.com/"
 	LeetCode             = "leetcode"
+	LeetCodeCnUrl        = "https://leetcode.cn/"
+	LeetCodeCn           = "leetcode-cn"
 )
 
 type Metadata struct {
@@ -44,18 +55,22 @@ type Metadata struct {
 	Filters *provider.Filters
 }
 
+// metadataLine matches the line of a solution holding its metadata
+var metadataLine = regexp.MustCompile("([\\w-]+) metadata: ")
+
 func GetMetadata(path string) (*Metadata, error) {
 	srcFile, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer srcFile.Close()
+
 	scanner := bufio.NewScanner(srcFile)
 	var parsedFilters provider.Filters
 	var parsedBackend string
 	for scanner.Scan() {
 		ln := scanner.Text()
-		re := regexp.MustCompile("([\\w-]+) metadata: ")
-		matches := re.FindStringSubmatch(ln)
+		matches := metadataLine.FindStringSubmatch(ln)
 		if len(matches) != 0 {
 			parsedBackend = matches[1]
 			log.Printf("metadata found for %s", backend)
@@ -70,17 +85,140 @@ func GetMetadata(path string) (*Metadata, error) {
 }
 
 func IsConfigCommand(cmd *cobra.Command) bool {
-	return strings.HasPrefix(cmd.Use, "login")
+	return strings.HasPrefix(cmd.Use, "login") || strings.HasPrefix(cmd.Use, "init")
+}
+
+// IsLocalCommand is true for commands which do not need to talk to a provider
+func IsLocalCommand(cmd *cobra.Command) bool {
+	return strings.HasPrefix(cmd.Use, "stress") || strings.HasPrefix(cmd.Use, "test") || strings.HasPrefix(cmd.Use, "stats") ||
+		strings.HasPrefix(cmd.Use, "tracks") || // the tracks of hackerrank are public
+		cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd ||
+		strings.HasPrefix(cmd.CommandPath(), "tinycode team") ||
+		strings.HasPrefix(cmd.CommandPath(), "tinycode review ") || // only `review` itself checks out problems
+		strings.HasPrefix(cmd.CommandPath(), "tinycode plan run") || // signs in to the provider of the list
+		strings.HasPrefix(cmd.CommandPath(), "tinycode plan status")
+}
+
+// IsServerCommand is true for commands which set up their own clients, as
+// requests come in
+func IsServerCommand(cmd *cobra.Command) bool {
+	return strings.HasPrefix(cmd.Use, "serve")
+}
+
+// findWorkspace looks up the workspace enclosing path (or the working directory
+// if path is empty), returning nil if there is none
+func findWorkspace(path string) *workspace.Workspace {
+	dir := path
+	if dir == "" {
+		dir = "."
+	} else if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	found, err := workspace.Find(dir)
+	if err != nil {
+		log.Printf("not in a workspace: %s", err)
+		return nil
+	}
+
+	log.Printf("using workspace at %s", found.Root)
+	return found
 }
 
 var client provider.Provider
 
+func newClient(backend string) (provider.Provider, error) {
+	switch backend {
+	case LeetCode:
+		base, _ := url.Parse(LeetCodeUrl)
+		return leetcode.NewClient(base), nil
+	case LeetCodeCn:
+		base, _ := url.Parse(LeetCodeCnUrl)
+		return leetcode.NewChinaClient(base), nil
+	case HackerRank:
+		base, _ := url.Parse(HackerRankUrl)
+		hrClient := hackerrank.NewClient(base)
+		hrClient.DoPurchase = doPurchase
+		hrClient.Pick = pickStr
+		hrClient.TaxonomyPath = hackerrank.TaxonomyPath(configPath)
+		if skipStr != "" {
+			hrClient.Skip = strings.Split(skipStr, ",")
+		}
+		return hrClient, nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s (must be hackerrank, leetcode or leetcode-cn)", backend)
+	}
+}
+
+// authenticate configures the client with the login of the backend found in
+// the configuration file, and checks that it is still valid
+func authenticate(client provider.Provider, backend string) error {
+	// read the configuration file and extract+apply the backend config
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("no configuration found: try running: tinycode login -p %s", backend)
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		return err
+	}
+
+	config, in := config.Backend[backend]
+	if !in {
+		return fmt.Errorf("no authentication token for %s found: try running: tinycode login -p %[1]s", backend)
+	}
+
+	if err := client.Configure(config); err != nil {
+		return err
+	}
+
+	// check if we are signed in, in order to check the validity of our token
+	if isSignedIn, err := client.IsSignedIn(); err != nil || !isSignedIn {
+		if err != nil {
+			log.Printf("error trying to check if signed in: %v", err)
+		} else {
+			log.Printf("not signed in")
+		}
+		return fmt.Errorf("current login config for %s invalid: try running: tinycode login -p %[1]s", backend)
+	} else {
+		log.Printf("valid authentication token found!")
+	}
+
+	return nil
+}
+
+// inferLang sets langStr, if not given, from the extension of the path passed
+// in argument or else from the workspace
+func inferLang() {
+	if langStr == "" && srcStr != "" {
+		spl := strings.Split(srcStr, ".")
+		if len(spl) > 1 {
+			ext := spl[len(spl)-1]
+			parsedLang, err := provider.ParseExt(ext)
+			if err == nil { // a match
+				langStr = parsedLang.String()
+			}
+		}
+	}
+
+	if langStr == "" && ws != nil {
+		langStr = ws.Config.Lang
+	}
+}
+
 var rootCmd = &cobra.Command{
 	Use:     "tinycode",
 	Short:   "Real hackers don't do competitive coding in the browser",
 	Example: `  tinycode checkout --difficulty easy --submit /tmp`,
 	Version: "0.2.0",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(outputStr); err != nil {
+			return err
+		}
+
+		if !isTextOutput() {
+			color.NoColor = true
+		}
+
 		if debug {
 			log.SetOutput(os.Stderr)
 		} else {
@@ -103,72 +241,44 @@ var rootCmd = &cobra.Command{
 			}
 		}
 
-		// if backend is not specified (and was not overridden by metadata), default
-		// is "hackerrank"
+		if !IsConfigCommand(cmd) {
+			ws = findWorkspace(srcStr)
+		}
+
+		// if backend is not specified (and was not overridden by metadata), use
+		// the workspace's default provider
+		if backend == "" && ws != nil {
+			backend = ws.Config.Provider
+		}
+
+		// if backend is still not specified, default is "hackerrank"
 		if backend == "" {
 			backend = HackerRank
 		}
 
 		// instantiate the backend client
-		switch backend {
-		case LeetCode:
-			base, _ := url.Parse(LeetCodeUrl)
-			client = leetcode.NewClient(base)
-		case HackerRank:
-			base, _ := url.Parse(HackerRankUrl)
-			hrClient := hackerrank.NewClient(base)
-			hrClient.DoPurchase = doPurchase
-			client = hrClient
-		default:
-			return fmt.Errorf("unknown provider: %s (must be hackerrank or leetcode)", backend)
+		var err error
+		if client, err = newClient(backend); err != nil {
+			return err
 		}
 
 		if IsConfigCommand(cmd) { // cmd is `login` or other configuration subcommand
 			return nil
 		}
 
-		// read the configuration file and extract+apply the backend config
-		if err := viper.ReadInConfig(); err != nil {
-			return fmt.Errorf("no configuration found: try running: tinycode login -p %s", backend)
-		}
-
-		if err := viper.Unmarshal(&config); err != nil {
-			return err
+		if IsLocalCommand(cmd) { // cmd runs locally, no need for authentication
+			return nil
 		}
 
-		config, in := config.Backend[backend]
-		if !in {
-			return fmt.Errorf("no authentication token for %s found: try running: tinycode login -p %[1]s", backend)
+		if IsServerCommand(cmd) { // cmd authenticates on its own
+			return nil
 		}
 
-		if err := client.Configure(config); err != nil {
+		if err := authenticate(client, backend); err != nil {
 			return err
 		}
 
-		// check if we are signed in, in order to check the validity of our token
-		if isSignedIn, err := client.IsSignedIn(); err != nil || !isSignedIn {
-			if err != nil {
-				log.Printf("error trying to check if signed in: %v", err)
-			} else {
-				log.Printf("not signed in")
-			}
-			return fmt.Errorf("current login config for %s invalid: try running: tinycode login -p %[1]s", backend)
-		} else {
-			log.Printf("valid authentication token found!")
-		}
-
-		// if --lang is not given but a path was passed in argument, attempt to
-		// recover a --lang value from the file path's extension (if it has an extension)
-		if langStr == "" && srcStr != "" {
-			spl := strings.Split(args[0], ".")
-			if len(spl) > 1 {
-				ext := spl[len(spl)-1]
-				parsedLang, err := provider.ParseExt(ext)
-				if err == nil { // a match
-					langStr = parsedLang.String()
-				}
-			}
-		}
+		inferLang()
 
 		if problemId != "" {
 			if err := filters.AddFilter("id", problemId); err != nil {
@@ -206,29 +316,153 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", configPathDefault, "the path to the configuration directory")
 	rootCmd.MarkFlagDirname("config")
-	rootCmd.PersistentFlags().StringVarP(&backend, "provider", "p", "", "which problem provider to use (leetcode or hackerrank)")
+	rootCmd.PersistentFlags().StringVarP(&backend, "provider", "p", "", "which problem provider to use (leetcode, leetcode-cn or hackerrank)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debugging output")
+	rootCmd.PersistentFlags().StringVarP(&outputStr, "output", "O", TextOutput, "output format (text, json or ndjson)")
 
 	checkoutCmd.Flags().StringVarP(&difficultyStr, "difficulty", "d", "", "limit search to a given difficulty (easy, medium, hard)")
 	checkoutCmd.Flags().StringVar(&statusStr, "status", "", "limit search to a given status (todo, attempted, solved)")
 	checkoutCmd.Flags().StringVarP(&tagsStr, "tags", "t", "", "limit search to a given list of (comma-separated) tags (leetcode only)")
+	checkoutCmd.Flags().StringVar(&categoryStr, "category", "", "limit search to a category of problems (algorithms, database, shell, concurrency or pandas, leetcode only)")
+	checkoutCmd.Flags().BoolVar(&doIncludePremium, "include-premium", false, "search premium problems too, even if your account cannot access them (leetcode only)")
 	checkoutCmd.Flags().StringVar(&problemSlug, "problem", "", "slug of a problem (e.g. two-sum)")
 	checkoutCmd.Flags().StringVar(&problemId, "id", "", "id of a problem (e.g. 1)")
 	checkoutCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the submission (e.g. cpp)")
 	checkoutCmd.Flags().StringVar(&contestSlug, "contest", "", "contest to which the problem belong (hackerrank only)")
 	checkoutCmd.Flags().BoolVarP(&doOpen, "open", "o", false, "whether to open the file")
 	checkoutCmd.Flags().StringVar(&trackStr, "track", "", "limit search to a given track (hackerrank only)")
+	checkoutCmd.Flags().StringVar(&skillsStr, "skills", "", "limit search to a given list of (comma-separated) skills (hackerrank only)")
+	checkoutCmd.Flags().StringVar(&playlistStr, "playlist", "", "check out the next problem of a playlist, e.g. interview-preparation-kit (hackerrank only)")
+	checkoutCmd.Flags().StringVar(&sectionStr, "section", "", "limit search to a section of the playlist (hackerrank only)")
+	checkoutCmd.Flags().StringVar(&pickStr, "pick", "", "how to pick the next problem, first (in order) or random (hackerrank only)")
+	checkoutCmd.Flags().StringVar(&skipStr, "skip", "", "a list of (comma-separated) problem slugs to pass over (hackerrank only)")
 	checkoutCmd.Flags().BoolVarP(&doSubmit, "submit", "s", false, "whether to open the file then submit after closing")
+	checkoutCmd.Flags().BoolVar(&doRecommend, "recommend", false, "pick the problem targeting your weak areas (see `tinycode next`) rather than any one")
+	registerSearchCompletions(checkoutCmd)
 	rootCmd.AddCommand(checkoutCmd)
 
 	submitCmd.Flags().StringVar(&problemSlug, "problem", "", "slug of a problem (e.g. two-sum)")
 	submitCmd.Flags().StringVar(&problemId, "id", "", "id of a problem (e.g. 1)")
 	submitCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the submission (e.g. cpp)")
 	submitCmd.Flags().BoolVar(&doPurchase, "purchase", false, "whether to purchase the last failed testcase (hackerrank only)")
+	submitCmd.Flags().BoolVarP(&doForce, "force", "f", false, "submit even if a pre-submit hook fails")
+	submitCmd.Flags().BoolVar(&doCommit, "commit", false, "commit the solution to its git repository if accepted")
 	rootCmd.AddCommand(submitCmd)
 
+	rootCmd.AddCommand(hintCmd)
+
+	listCmd.Flags().StringVarP(&difficultyStr, "difficulty", "d", "", "limit search to a given difficulty (easy, medium, hard)")
+	listCmd.Flags().StringVar(&statusStr, "status", "", "limit search to a given status (todo, attempted, solved)")
+	listCmd.Flags().StringVarP(&tagsStr, "tags", "t", "", "limit search to a given list of (comma-separated) tags (leetcode only)")
+	listCmd.Flags().StringVar(&categoryStr, "category", "", "limit search to a category of problems (algorithms, database, shell, concurrency or pandas, leetcode only)")
+	listCmd.Flags().BoolVar(&doIncludePremium, "include-premium", false, "search premium problems too, even if your account cannot access them (leetcode only)")
+	listCmd.Flags().StringVar(&trackStr, "track", "", "limit search to a given track (hackerrank only)")
+	listCmd.Flags().StringVar(&skillsStr, "skills", "", "limit search to a given list of (comma-separated) skills (hackerrank only)")
+	listCmd.Flags().StringVar(&playlistStr, "playlist", "", "list the problems of a playlist, e.g. interview-preparation-kit (hackerrank only)")
+	listCmd.Flags().StringVar(&sectionStr, "section", "", "limit search to a section of the playlist (hackerrank only)")
+	listCmd.Flags().StringVar(&contestSlug, "contest", "", "contest to which the problems belong (hackerrank only)")
+	listCmd.Flags().Uint64Var(&offset, "offset", 0, "number of problems to skip")
+	listCmd.Flags().Uint64Var(&limit, "limit", 50, "maximum number of problems to list")
+	registerSearchCompletions(listCmd)
+	rootCmd.AddCommand(listCmd)
+
+	nextCmd.Flags().StringVarP(&difficultyStr, "difficulty", "d", "", "limit search to a given difficulty (easy, medium, hard)")
+	nextCmd.Flags().StringVar(&statusStr, "status", "", "limit search to a given status (todo, attempted)")
+	nextCmd.Flags().StringVarP(&tagsStr, "tags", "t", "", "limit search to a given list of (comma-separated) tags (leetcode only)")
+	nextCmd.Flags().StringVar(&categoryStr, "category", "", "limit search to a category of problems (algorithms, database, shell, concurrency or pandas, leetcode only)")
+	nextCmd.Flags().BoolVar(&doIncludePremium, "include-premium", false, "search premium problems too, even if your account cannot access them (leetcode only)")
+	nextCmd.Flags().StringVar(&trackStr, "track", "", "limit search to a given track (hackerrank only)")
+	nextCmd.Flags().StringVar(&skillsStr, "skills", "", "limit search to a given list of (comma-separated) skills (hackerrank only)")
+	nextCmd.Flags().StringVar(&contestSlug, "contest", "", "contest to which the problems belong (hackerrank only)")
+	nextCmd.Flags().IntVarP(&recommendCount, "count", "n", 1, "number of problems to recommend")
+	nextCmd.Flags().BoolVar(&doExplain, "explain", false, "show why each problem is recommended")
+	registerSearchCompletions(nextCmd)
+	rootCmd.AddCommand(nextCmd)
+
+	tracksCmd.Flags().StringVar(&trackStr, "track", "", "only show the given track")
+	tracksCmd.Flags().BoolVar(&doRefresh, "refresh", false, "fetch the tracks again rather than using those kept since last time")
+	registerSearchCompletions(tracksCmd)
+	rootCmd.AddCommand(tracksCmd)
+
+	playlistCmd.Flags().StringVar(&sectionStr, "section", "", "only show the given section, along with its problems")
+	rootCmd.AddCommand(playlistCmd)
+
+	stressCmd.Flags().StringVar(&bruteStr, "brute", "", "path to a brute-force reference solution")
+	stressCmd.Flags().StringVar(&genStr, "gen", "", "path to a random input generator, passed the seed as its only argument")
+	stressCmd.Flags().Uint64Var(&iterations, "iterations", 1000, "number of random inputs to try")
+	stressCmd.Flags().Int64Var(&seed, "seed", 0, "seed of the first iteration (DEFAULT: random)")
+	stressCmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "number of parallel workers")
+	stressCmd.Flags().DurationVar(&timeout, "timeout", 2*time.Second, "cpu time limit of a single run (wall time is twice that)")
+	stressCmd.Flags().Uint64Var(&memoryLimit, "memory", 256, "memory limit of a single run, in MB")
+	stressCmd.Flags().Uint64Var(&outputLimit, "output-limit", 64, "output limit of a single run, in MB")
+	stressCmd.Flags().StringVar(&saveStr, "save", "", "where to save the failing input (DEFAULT: PATH with a .fail.in extension)")
+	stressCmd.Flags().IntVar(&minimizeBudget, "minimize", 200, "maximum number of runs spent minimizing the failing input (0 to disable)")
+	stressCmd.Flags().StringVarP(&langStr, "lang", "l", "", "language of the solution (DEFAULT: from the file extension)")
+	rootCmd.AddCommand(stressCmd)
+
+	testCmd.Flags().DurationVar(&timeout, "timeout", 2*time.Second, "cpu time limit of a single run (wall time is twice that)")
+	testCmd.Flags().Uint64Var(&memoryLimit, "memory", 256, "memory limit of a single run, in MB")
+	testCmd.Flags().Uint64Var(&outputLimit, "output-limit", 64, "output limit of a single run, in MB")
+	testCmd.Flags().StringVarP(&langStr, "lang", "l", "", "language of the solution (DEFAULT: from the file extension)")
+	rootCmd.AddCommand(testCmd)
+
+	watchCmd.Flags().DurationVar(&timeout, "timeout", 2*time.Second, "cpu time limit of a single run (wall time is twice that)")
+	watchCmd.Flags().Uint64Var(&memoryLimit, "memory", 256, "memory limit of a single run, in MB")
+	watchCmd.Flags().Uint64Var(&outputLimit, "output-limit", 64, "output limit of a single run, in MB")
+	watchCmd.Flags().StringVarP(&langStr, "lang", "l", "", "language of the solution (DEFAULT: from the file extension)")
+	watchCmd.Flags().BoolVarP(&doForce, "force", "f", false, "submit even if a pre-submit hook fails")
+	rootCmd.AddCommand(watchCmd)
+
+	statsCmd.Flags().BoolVar(&doLocal, "local", false, "only report on the local history, without signing in to the provider")
+	statsCmd.Flags().StringVar(&htmlStr, "html", "", "also write a self-contained HTML report to the given path")
+	rootCmd.AddCommand(statsCmd)
+
+	teamReportCmd.Flags().StringVar(&repoStr, "repo", ".", "path to the git repository holding the solutions")
+	teamReportCmd.MarkFlagDirname("repo")
+	teamReportCmd.Flags().StringVar(&weekStr, "week", "", "a day of the (last) week to report on, e.g. 2022-05-16 (default today)")
+	teamReportCmd.Flags().IntVar(&weeks, "weeks", 1, "number of weeks to report on")
+	teamReportCmd.Flags().StringVar(&formatStr, "format", MarkdownFormat, "format of the report (markdown or html)")
+	teamReportCmd.Flags().BoolVar(&doVerified, "verified", false, "only count solutions known to be accepted")
+	teamReportCmd.Flags().BoolVar(&doRemote, "remote", false, "fetch the difficulty of problems unknown to the local history from their provider")
+	teamCmd.AddCommand(teamReportCmd)
+	rootCmd.AddCommand(teamCmd)
+
+	reviewCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the review (default is the one of the first accepted solution)")
+	reviewListCmd.Flags().BoolVar(&doAll, "all", false, "list every problem in the queue, whatever its provider and due date")
+	reviewCmd.AddCommand(reviewListCmd)
+	reviewCmd.AddCommand(reviewGradeCmd)
+	reviewCmd.AddCommand(reviewExportCmd)
+	reviewCmd.AddCommand(reviewImportCmd)
+	rootCmd.AddCommand(reviewCmd)
+
+	planRunCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the submission (default is the one of the list)")
+	planImportCmd.Flags().StringVar(&favoritesStr, "favorites", "", "name of a list of favorites to import")
+	planImportCmd.Flags().StringVar(&studyPlanStr, "study-plan", "", "slug of a study plan to import (e.g. top-interview-150)")
+	planImportCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the list")
+	planCmd.AddCommand(planRunCmd)
+	planCmd.AddCommand(planStatusCmd)
+	planCmd.AddCommand(planImportCmd)
+	rootCmd.AddCommand(planCmd)
+
+	tuiCmd.Flags().StringVarP(&difficultyStr, "difficulty", "d", "", "limit search to a given difficulty (easy, medium, hard)")
+	tuiCmd.Flags().StringVar(&statusStr, "status", "", "limit search to a given status (todo, attempted, solved)")
+	tuiCmd.Flags().StringVarP(&tagsStr, "tags", "t", "", "limit search to a given list of (comma-separated) tags (leetcode only)")
+	tuiCmd.Flags().StringVar(&trackStr, "track", "", "limit search to a given track (hackerrank only)")
+	tuiCmd.Flags().StringVar(&contestSlug, "contest", "", "contest to which the problems belong (hackerrank only)")
+	tuiCmd.Flags().StringVarP(&langStr, "lang", "l", "", "target language of the solutions (e.g. cpp)")
+	registerSearchCompletions(tuiCmd)
+	rootCmd.AddCommand(tuiCmd)
+
+	serveCmd.Flags().BoolVar(&serveStdio, "stdio", false, "serve requests on stdin, and answer them on stdout")
+	rootCmd.AddCommand(serveCmd)
+
+	initCmd.Flags().StringVarP(&langStr, "lang", "l", "", "default language of the workspace (e.g. rust)")
+	initCmd.Flags().StringVar(&layoutStr, "layout", workspace.DefaultLayout, "layout of problem directories within the workspace")
+	initCmd.Flags().StringVar(&javaBuildStr, "java-build", workspace.Gradle, "build tool for java projects (gradle or maven)")
+	rootCmd.AddCommand(initCmd)
+
 	loginCmd.Flags().StringVarP(&csrf, "csrf", "c", "", "Manually set the X-CSRF-Token")
-	loginCmd.Flags().StringVarP(&session, "session", "s", "", "Manually set the session token (_hrank_session for hackerrank, LEETCODE_SESSION for leetcode)")
+	loginCmd.Flags().StringVarP(&session, "session", "s", "", "Manually set the session token (_hrank_session for hackerrank, LEETCODE_SESSION for leetcode and leetcode-cn)")
 	rootCmd.AddCommand(loginCmd)
 
 	rootCmd.SilenceUsage = true
@@ -237,13 +471,23 @@ func init() {
 	viper.SetConfigName("config")
 	viper.SetConfigType("toml")
 	viper.SetDefault("backend.leetcode.csrf-header", "X-csrftoken")
+	viper.SetDefault("backend.leetcode-cn.csrf-header", "X-csrftoken")
 	viper.SetDefault("backend.hackerrank.csrf-header", "X-CSRF-Token")
 	viper.AddConfigPath(configPath)
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "tinycode: %s", err)
-		os.Exit(1)
+		var exit *exitCodeError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+
+		if isTextOutput() {
+			fmt.Fprintf(os.Stderr, "tinycode: %s", err)
+		} else {
+			emit(errorDocument{Error: err.Error()})
+		}
+		os.Exit(ExitError)
 	}
 }
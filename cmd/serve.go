@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/jsonrpc"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/runner"
+	"github.com/brokad/tinycode/sandbox"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Flags and parameters
+var serveStdio bool
+
+// serveParams are the parameters of all methods, each using the ones it needs
+type serveParams struct {
+	Provider    string            `json:"provider,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Lang        string            `json:"lang,omitempty"`
+	Filters     map[string]string `json:"filters,omitempty"` // e.g. slug, id, contest, difficulty, status, tags or track
+	Offset      uint64            `json:"offset,omitempty"`
+	Limit       uint64            `json:"limit,omitempty"`
+	Input       string            `json:"input,omitempty"`
+	Expected    *string           `json:"expected,omitempty"`
+	Force       bool              `json:"force,omitempty"`
+	Timeout     int64             `json:"timeout_ms,omitempty"`
+	Memory      uint64            `json:"memory,omitempty"`       // in MB
+	OutputLimit uint64            `json:"output_limit,omitempty"` // in MB
+}
+
+// progressDocument is sent as a notification while a request is in progress
+type progressDocument struct {
+	Id     *json.RawMessage   `json:"id"`
+	Sample *sampleDocument    `json:"sample,omitempty"`
+	Judge  *provider.Progress `json:"judge,omitempty"`
+}
+
+type statusDocument struct {
+	Version   string `json:"version"`
+	Provider  string `json:"provider"`
+	SignedIn  bool   `json:"signed_in"`
+	Error     string `json:"error,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+}
+
+// server answers requests one at a time, setting the same state as flags do
+// before reusing the code of the matching commands
+type server struct {
+	conn    *jsonrpc.Conn
+	clients map[string]provider.Provider
+}
+
+// client returns the client of backend, configured and signed in the first time
+// it is needed and kept around after that
+func (s *server) client(backend string) (provider.Provider, error) {
+	if found, ok := s.clients[backend]; ok {
+		return found, nil
+	}
+
+	created, err := newClient(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authenticate(created, backend); err != nil {
+		return nil, err
+	}
+
+	s.clients[backend] = created
+	return created, nil
+}
+
+// prepare sets the state used by commands from the parameters of a request,
+// with a client if remote is set
+func (s *server) prepare(params serveParams, remote bool) error {
+	backend = params.Provider
+	srcStr = params.Path
+	langStr = params.Lang
+	filters = provider.Filters{}
+	doForce = params.Force
+
+	timeout = 2 * time.Second
+	if params.Timeout != 0 {
+		timeout = time.Duration(params.Timeout) * time.Millisecond
+	}
+	memoryLimit = 256
+	if params.Memory != 0 {
+		memoryLimit = params.Memory
+	}
+	outputLimit = 64
+	if params.OutputLimit != 0 {
+		outputLimit = params.OutputLimit
+	}
+
+	if srcStr != "" {
+		if metadata, err := GetMetadata(srcStr); err == nil {
+			if backend == "" && metadata.Backend != "" {
+				backend = metadata.Backend
+			}
+			filters.Update(metadata.Filters)
+		}
+	}
+
+	ws = findWorkspace(srcStr)
+
+	if backend == "" && ws != nil {
+		backend = ws.Config.Provider
+	}
+
+	if backend == "" {
+		backend = HackerRank
+	}
+
+	inferLang()
+
+	for name, value := range params.Filters {
+		if err := filters.AddFilter(name, value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := filters.GetFilter("contest"); err != nil && backend == HackerRank {
+		_ = filters.AddFilter("contest", "master")
+	}
+
+	if !remote {
+		return nil
+	}
+
+	var err error
+	client, err = s.client(backend)
+	return err
+}
+
+func (s *server) lang() (*provider.Lang, error) {
+	if langStr == "" {
+		return nil, nil
+	}
+	return provider.ParseLang(langStr)
+}
+
+func (s *server) status(params serveParams) (interface{}, error) {
+	if err := s.prepare(params, false); err != nil {
+		return nil, err
+	}
+
+	document := statusDocument{
+		Version:  rootCmd.Version,
+		Provider: backend,
+		Lang:     langStr,
+	}
+	if ws != nil {
+		document.Workspace = ws.Root
+	}
+
+	if _, err := s.client(backend); err != nil {
+		document.Error = err.Error()
+	} else {
+		document.SignedIn = true
+	}
+
+	return document, nil
+}
+
+func (s *server) checkout(params serveParams) (interface{}, error) {
+	if err := s.prepare(params, true); err != nil {
+		return nil, err
+	}
+
+	if err := resolveChallenge(); err != nil {
+		return nil, err
+	}
+
+	document, _, err := checkoutChallenge()
+	return document, err
+}
+
+func (s *server) list(params serveParams) (interface{}, error) {
+	if err := s.prepare(params, true); err != nil {
+		return nil, err
+	}
+
+	if params.Limit == 0 {
+		params.Limit = 50
+	}
+
+	summaries, err := client.List(filters, params.Offset, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return newListDocument(summaries), nil
+}
+
+func (s *server) test(id *json.RawMessage, params serveParams) (interface{}, error) {
+	if err := s.prepare(params, false); err != nil {
+		return nil, err
+	}
+
+	lang, err := s.lang()
+	if err != nil {
+		return nil, err
+	}
+
+	return runSamples(srcStr, lang, func(sample runner.Sample, verdict *sandbox.Verdict, result sampleDocument) {
+		if err := s.conn.Notify("progress", progressDocument{Id: id, Sample: &result}); err != nil {
+			log.Printf("could not send progress: %s", err)
+		}
+	})
+}
+
+func (s *server) run(params serveParams) (interface{}, error) {
+	if err := s.prepare(params, false); err != nil {
+		return nil, err
+	}
+
+	lang, err := s.lang()
+	if err != nil {
+		return nil, err
+	}
+
+	solution, err := buildProgram(srcStr, lang)
+	if err != nil {
+		return nil, err
+	}
+	defer solution.Close()
+
+	verdict, err := solution.Run(context.Background(), params.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSampleDocument(runner.Sample{Input: params.Input, Expected: params.Expected}, verdict), nil
+}
+
+func (s *server) submit(id *json.RawMessage, params serveParams) (interface{}, error) {
+	if params.Path == "" {
+		return nil, fmt.Errorf("a path must be provided")
+	}
+
+	if err := s.prepare(params, true); err != nil {
+		return nil, err
+	}
+
+	if observable, ok := client.(provider.Observable); ok {
+		observable.OnProgress(func(progress provider.Progress) {
+			if err := s.conn.Notify("progress", progressDocument{Id: id, Judge: &progress}); err != nil {
+				log.Printf("could not send progress: %s", err)
+			}
+		})
+		defer observable.OnProgress(nil)
+	}
+
+	_, document, err := submitSolution(srcStr)
+	return document, err
+}
+
+func (s *server) handle(req *jsonrpc.Request) (interface{}, error) {
+	params := serveParams{}
+	if len(req.Params) != 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.InvalidParams, "invalid params: %s", err)
+		}
+	}
+
+	switch req.Method {
+	case "status":
+		return s.status(params)
+	case "checkout":
+		return s.checkout(params)
+	case "list":
+		return s.list(params)
+	case "test":
+		return s.test(req.Id, params)
+	case "run":
+		return s.run(params)
+	case "submit":
+		return s.submit(req.Id, params)
+	case "shutdown", "exit":
+		return nil, nil
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.MethodNotFound, "unknown method: %s", req.Method)
+	}
+}
+
+func (s *server) serve() error {
+	for {
+		req, err := s.conn.Read()
+
+		var rpcErr *jsonrpc.Error
+		if errors.As(err, &rpcErr) {
+			if err := s.conn.ReplyError(nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		} else if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		log.Printf("request: %s", req.Method)
+		result, err := s.handle(req)
+
+		if req.IsNotification() {
+			if err != nil {
+				log.Printf("notification %s failed: %s", req.Method, err)
+			}
+		} else if err != nil {
+			if !errors.As(err, &rpcErr) {
+				rpcErr = jsonrpc.NewError(jsonrpc.InternalError, "%s", err)
+			}
+			if err := s.conn.ReplyError(req.Id, rpcErr); err != nil {
+				return err
+			}
+		} else if err := s.conn.Reply(req.Id, result); err != nil {
+			return err
+		}
+
+		if req.Method == "shutdown" || req.Method == "exit" {
+			return nil
+		}
+	}
+}
+
+var serveCmd = &cobra.Command{
+	Use:     "serve --stdio",
+	Short:   "serve JSON-RPC requests, e.g. from an editor plugin",
+	Example: `  tinycode serve --stdio`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !serveStdio {
+			return fmt.Errorf("only --stdio is supported")
+		}
+
+		// stdout carries the protocol, so nothing else may be printed on it
+		outputStr = JsonOutput
+		color.NoColor = true
+
+		s := server{
+			conn:    jsonrpc.NewConn(os.Stdin, os.Stdout),
+			clients: map[string]provider.Provider{},
+		}
+		return s.serve()
+	},
+}
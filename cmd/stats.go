@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/stats"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Flags and parameters
+var htmlStr string
+var doLocal bool
+
+// statsTopCounts is the number of tags or languages shown in text output
+const statsTopCounts = 8
+
+// recordHistory appends entry to the local history, which only feeds
+// statistics and so never fails a command
+func recordHistory(entry history.Entry) {
+	if err := history.Append(history.Path(configPath), entry); err != nil {
+		log.Printf("could not record history: %s", err)
+	}
+}
+
+func formatCounts(counts []stats.Count) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+
+	var parts []string
+	for i, count := range counts {
+		if i == statsTopCounts {
+			parts = append(parts, fmt.Sprintf("and %d more", len(counts)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", count.Name, count.Count))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// countsOf sorts counts by difficulty if they are known ones, and by count
+// otherwise
+func countsOf(counts map[string]uint64) []stats.Count {
+	var output []stats.Count
+	for name, count := range counts {
+		output = append(output, stats.Count{Name: name, Count: count})
+	}
+
+	rank := map[string]int{"easy": 1, "medium": 2, "hard": 3}
+	sort.Slice(output, func(i, j int) bool {
+		a, b := output[i], output[j]
+		if rank[a.Name] != rank[b.Name] {
+			return rank[a.Name] < rank[b.Name]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	return output
+}
+
+func printStats(report stats.Report) {
+	bold := color.New(color.Bold)
+	row := func(label string, format string, args ...interface{}) {
+		fmt.Printf("  %s %s\n", bold.Sprintf("%-16s", label), fmt.Sprintf(format, args...))
+	}
+
+	fmt.Printf("%s\n\n", bold.Sprintf("Progress on %s", report.Provider))
+
+	row("Solved", "%d (and %d attempted, not solved yet)", report.Solved, report.Attempted)
+	row("Acceptance", "%s (%d/%d submissions)", stats.FormatPercent(report.AcceptanceRate), report.Accepted, report.Submissions)
+	row("Streak", "%s (longest: %s)", pluralDays(report.CurrentStreak), pluralDays(report.LongestStreak))
+	row("Time to accept", "%s (median, since checkout)", stats.FormatDuration(report.MedianTimeToAcceptDuration()))
+	fmt.Println()
+	row("By difficulty", "%s", formatCounts(countsOf(countMap(report.ByDifficulty))))
+	row("By tag", "%s", formatCounts(report.ByTag))
+	row("By language", "%s", formatCounts(report.ByLang))
+
+	var weakest []string
+	for _, topic := range report.WeakestTopics {
+		weakest = append(weakest, fmt.Sprintf("%s %s (%d/%d)", topic.Tag, stats.FormatPercent(topic.AcceptanceRate), topic.Accepted, topic.Submissions))
+	}
+	if len(weakest) == 0 {
+		weakest = []string{"-"}
+	}
+	row("Weakest topics", "%s", strings.Join(weakest, " · "))
+
+	profile := report.Profile
+	if profile == nil {
+		return
+	}
+
+	fmt.Printf("\n%s\n\n", bold.Sprintf("Profile of %s", profile.Username))
+
+	if len(profile.Solved) != 0 {
+		row("Solved", "%s", formatCounts(countsOf(profile.Solved)))
+	}
+	if len(profile.Attempted) != 0 {
+		row("Attempted", "%s", formatCounts(countsOf(profile.Attempted)))
+	}
+	if len(profile.Untouched) != 0 {
+		row("Left", "%s", formatCounts(countsOf(profile.Untouched)))
+	}
+	if profile.Submissions != 0 {
+		rate := float64(profile.Accepted) / float64(profile.Submissions)
+		row("Acceptance", "%s (%d/%d submissions)", stats.FormatPercent(rate), profile.Accepted, profile.Submissions)
+	}
+	if len(profile.Tags) != 0 {
+		row("By tag", "%s", formatCounts(countsOf(profile.Tags)))
+	}
+	if len(profile.Langs) != 0 {
+		row("By language", "%s", formatCounts(countsOf(profile.Langs)))
+	}
+	for _, badge := range profile.Badges {
+		row("Badge", "%s %s (%d/%d solved)", badge.Name, strings.Repeat("★", int(badge.Stars)), badge.Solved, badge.Total)
+	}
+	for _, score := range profile.Scores {
+		row("Score", "%s %.0f (rank %d)", score.Track, score.Score, score.Rank)
+	}
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+func countMap(counts []stats.Count) map[string]uint64 {
+	output := map[string]uint64{}
+	for _, count := range counts {
+		output[count.Name] = count.Count
+	}
+	return output
+}
+
+// fetchProfile signs in to the provider to fetch the profile of the user, if
+// it has one
+func fetchProfile() (*provider.Profile, error) {
+	profiled, ok := client.(provider.Profiled)
+	if !ok {
+		return nil, nil
+	}
+
+	if err := authenticate(client, backend); err != nil {
+		return nil, err
+	}
+
+	return profiled.Profile()
+}
+
+var statsCmd = &cobra.Command{
+	Use:     "stats [--local] [--html PATH]",
+	Short:   "report on your progress, from the provider and the local history of submissions",
+	Example: `  tinycode stats -p leetcode --html stats.html`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := history.Read(history.Path(configPath))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		report := stats.Compute(backend, entries, now)
+
+		if !doLocal {
+			if profile, err := fetchProfile(); err != nil {
+				fmt.Fprintf(os.Stderr, "tinycode: could not fetch your profile (%s), showing local statistics only\n", err)
+			} else {
+				report.Profile = profile
+			}
+		}
+
+		if htmlStr != "" {
+			f, err := os.Create(htmlStr)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := stats.RenderHtml(f, report, now); err != nil {
+				return err
+			}
+			log.Printf("wrote report to %s", htmlStr)
+		}
+
+		if !isTextOutput() {
+			return emit(report)
+		}
+
+		printStats(report)
+		return nil
+	},
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/runner"
+	"github.com/brokad/tinycode/sandbox"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var bruteStr string
+var genStr string
+var iterations uint64
+var seed int64
+var jobs int
+var timeout time.Duration
+var memoryLimit uint64
+var outputLimit uint64
+var saveStr string
+var minimizeBudget int
+
+func runLimits() sandbox.Limits {
+	return sandbox.Limits{
+		CPUTime:  timeout,
+		WallTime: 2 * timeout,
+		Memory:   memoryLimit << 20,
+		Output:   outputLimit << 20,
+		Isolate:  true,
+	}
+}
+
+type mismatchDocument struct {
+	Seed     int64                 `json:"seed"`
+	Class    provider.VerdictClass `json:"class"`
+	Exit     string                `json:"exit"`
+	Input    string                `json:"input"`
+	Saved    string                `json:"saved"`
+	Expected string                `json:"expected"`
+	Actual   string                `json:"actual"`
+	Stderr   string                `json:"stderr,omitempty"`
+}
+
+type stressDocument struct {
+	Path       string            `json:"path"`
+	Seed       int64             `json:"seed"`
+	Iterations uint64            `json:"iterations"`
+	Mismatch   *mismatchDocument `json:"mismatch,omitempty"`
+}
+
+func buildProgram(path string, lang *provider.Lang) (*runner.Program, error) {
+	if lang == nil {
+		parsed, err := runner.LangFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+		lang = parsed
+	}
+	log.Printf("building %s (%s)", path, lang.Pretty())
+
+	program, err := runner.Build(path, *lang)
+	if err != nil {
+		return nil, err
+	}
+	program.Limits = runLimits()
+
+	return program, nil
+}
+
+func mismatchReport(mismatch *runner.Mismatch, savedTo string) provider.ErrorReport {
+	input := strings.TrimRight(mismatch.Input, "\n")
+	expected := strings.TrimRight(mismatch.Expected.Stdout, "\n")
+	header := fmt.Sprintf("on input (seed %d, saved to %s):", mismatch.Seed, savedTo)
+
+	actual := mismatch.Actual
+	usage := fmt.Sprintf("cpu time %s, memory %d MB", actual.CPUTime.Round(time.Millisecond), actual.Memory>>20)
+
+	if actual.Status == sandbox.TimeLimitExceeded {
+		return provider.NewErrorReport(
+			actual.Status.String(),
+			fmt.Sprintf("solution took longer than %s (%s)", timeout, usage),
+			header,
+			fmt.Sprintf("%s\n\nexpected output:\n%s\n", input, expected),
+		)
+	} else if !actual.HasSucceeded() {
+		return provider.NewErrorReport(
+			actual.Status.String(),
+			fmt.Sprintf("solution %s (%s)", actual.Describe(), usage),
+			header,
+			fmt.Sprintf("%s\n\nexpected output:\n%s\n\nstderr:\n%s", input, expected, actual.Stderr),
+		)
+	} else {
+		return provider.NewErrorReport(
+			"wrong answer",
+			"solution and reference disagree",
+			header,
+			fmt.Sprintf("%s\n\nexpected:\n%s\n\ngot:\n%s\n", input, expected, strings.TrimRight(mismatch.Actual.Stdout, "\n")),
+		)
+	}
+}
+
+var stressCmd = &cobra.Command{
+	Use:     "stress --brute BRUTE --gen GEN [--iterations N] [--seed S] [--jobs J] [--timeout T] [--memory MB] [-l LANG] PATH",
+	Short:   "compare a solution against a brute-force reference on random inputs",
+	Example: `  tinycode stress --brute brute.py --gen gen.py solution.cpp`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if bruteStr == "" || genStr == "" {
+			return fmt.Errorf("both a --brute reference and a --gen generator must be provided")
+		}
+
+		var lang *provider.Lang
+		if langStr != "" {
+			parsed, err := provider.ParseLang(langStr)
+			if err != nil {
+				return err
+			}
+			lang = parsed
+		}
+
+		solution, err := buildProgram(srcStr, lang)
+		if err != nil {
+			return err
+		}
+		defer solution.Close()
+
+		brute, err := buildProgram(bruteStr, nil)
+		if err != nil {
+			return err
+		}
+		defer brute.Close()
+
+		gen, err := buildProgram(genStr, nil)
+		if err != nil {
+			return err
+		}
+		defer gen.Close()
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano() % 1000000
+		}
+
+		config := runner.StressConfig{
+			Iterations: iterations,
+			Seed:       seed,
+			Jobs:       jobs,
+		}
+
+		if isTextOutput() {
+			fmt.Fprintf(os.Stderr, "stress testing %s with seeds %d to %d\n", srcStr, seed, seed+int64(iterations)-1)
+		}
+
+		ctx := context.Background()
+		mismatch, done, err := runner.Stress(ctx, solution, brute, gen, config)
+		if err != nil {
+			return err
+		}
+
+		document := stressDocument{Path: srcStr, Seed: seed, Iterations: done}
+
+		if mismatch == nil {
+			if !isTextOutput() {
+				return emit(document)
+			}
+			header := color.New(color.Bold, color.FgGreen)
+			fmt.Fprintf(os.Stderr, "\n    %s %d iterations, no mismatch found\n", header.Sprintf("Finished"), done)
+			return nil
+		}
+
+		if mismatch.Actual.Status != sandbox.TimeLimitExceeded && minimizeBudget > 0 {
+			log.Printf("minimizing failing input of seed %d", mismatch.Seed)
+			mismatch = runner.Minimize(ctx, solution, brute, mismatch, minimizeBudget)
+		}
+
+		savePath := saveStr
+		if savePath == "" {
+			savePath = fmt.Sprintf("%s.fail.in", strings.TrimSuffix(srcStr, filepath.Ext(srcStr)))
+		}
+
+		if err := os.WriteFile(savePath, []byte(mismatch.Input), 0644); err != nil {
+			return err
+		}
+
+		class := localClass(mismatch.Actual, false)
+
+		if !isTextOutput() {
+			document.Mismatch = &mismatchDocument{
+				Seed:     mismatch.Seed,
+				Class:    class,
+				Exit:     mismatch.Actual.Describe(),
+				Input:    mismatch.Input,
+				Saved:    savePath,
+				Expected: mismatch.Expected.Stdout,
+				Actual:   mismatch.Actual.Stdout,
+				Stderr:   mismatch.Actual.Stderr,
+			}
+			if err := emit(document); err != nil {
+				return err
+			}
+		} else {
+			printErrorReport(mismatchReport(mismatch, savePath))
+		}
+
+		return exitWith(exitCode(class))
+	},
+}
@@ -1,142 +1,304 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/brokad/tinycode/autocommit"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/hooks"
 	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/workspace"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"io"
 	"log"
 	"math"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
-func printSubmitReportAndExit(report provider.SubmissionReport) {
-	if report.HasSucceeded() {
-		log.Printf("%s: run succeeded", report.Identify())
+// Flags and parameters
+var doForce bool
+var doCommit bool
 
-		var stats = report.Statistics()
+func formatStatistics(stats provider.SubmissionStatistics) string {
+	var buf strings.Builder
 
-		var buf strings.Builder
+	header := color.New(color.Bold, color.FgGreen)
+	header.Fprintf(&buf, "Finished")
 
-		header := color.New(color.Bold, color.FgGreen)
-		header.Fprintf(&buf, "Finished")
-
-		if tc := stats.TotalTestCases; tc != 0 {
-			fmt.Fprintf(&buf, " %d testcase", tc)
-			if tc > 1 {
-				fmt.Fprintf(&buf, "s")
-			}
+	if tc := stats.TotalTestCases; tc != 0 {
+		fmt.Fprintf(&buf, " %d testcase", tc)
+		if tc > 1 {
+			fmt.Fprintf(&buf, "s")
 		}
+	}
 
-		fmt.Fprintf(&buf, " done")
+	fmt.Fprintf(&buf, " done")
 
-		if rt := stats.Runtime; rt != "" {
-			fmt.Fprintf(&buf, " in %s", rt)
-		}
+	if rt := stats.Runtime; rt != "" {
+		fmt.Fprintf(&buf, " in %s", rt)
+	}
 
-		if rtp := stats.RuntimePercentile; !math.IsNaN(rtp) {
-			fmt.Fprintf(&buf, " (better than %f%%)", rtp)
-		}
+	if rtp := stats.RuntimePercentile; !math.IsNaN(rtp) {
+		fmt.Fprintf(&buf, " (better than %f%%)", rtp)
+	}
 
-		if mem := stats.Memory; mem != "" {
-			fmt.Fprintf(&buf, " and using %s", mem)
-		}
+	if mem := stats.Memory; mem != "" {
+		fmt.Fprintf(&buf, " and using %s", mem)
+	}
 
-		if memp := stats.MemoryPercentile; !math.IsNaN(memp) {
-			fmt.Fprintf(&buf, " (better than %f%%)", memp)
-		}
+	if memp := stats.MemoryPercentile; !math.IsNaN(memp) {
+		fmt.Fprintf(&buf, " (better than %f%%)", memp)
+	}
 
-		if score := stats.Score; score != "" {
-			fmt.Fprintf(&buf, " and earned %s", score)
-		}
+	if score := stats.Score; score != "" {
+		fmt.Fprintf(&buf, " and earned %s", score)
+	}
+
+	if maxs := stats.MaxScore; maxs != "" {
+		fmt.Fprintf(&buf, " (out of %s)", maxs)
+	}
 
-		if maxs := stats.MaxScore; maxs != "" {
-			fmt.Fprintf(&buf, " (out of %s)", maxs)
+	return buf.String()
+}
+
+func printStatistics(stats provider.SubmissionStatistics) {
+	fmt.Fprintf(os.Stderr, "\n    %s", formatStatistics(stats))
+}
+
+func formatErrorReport(errorReport provider.ErrorReport) string {
+	header := color.New(color.Bold, color.FgRed)
+	bold := color.New(color.Bold)
+	ctx := color.New(color.FgCyan, color.Bold)
+
+	var buf strings.Builder
+	buf.WriteString(header.Sprintf(errorReport.ErrorClass))
+	buf.WriteString(bold.Sprintf(": %s\n", errorReport.ErrorMsg))
+	if errorReport.CtxHeader != "" {
+		buf.WriteString(ctx.Sprintf("  ---> "))
+		buf.WriteString(fmt.Sprintln(errorReport.CtxHeader))
+	}
+	if errorReport.CtxMsg != "" {
+		buf.WriteString(ctx.Sprintf("  | \n"))
+		for _, line := range strings.Split(errorReport.CtxMsg, "\n") {
+			buf.WriteString(ctx.Sprintf("  | "))
+			buf.WriteString(line)
+			buf.WriteString("\n")
 		}
+	}
+	return buf.String()
+}
 
-		fmt.Fprintf(os.Stderr, "\n    %s", buf.String())
+func printErrorReport(errorReport provider.ErrorReport) {
+	fmt.Fprintf(os.Stderr, "\n%s\n", formatErrorReport(errorReport))
+}
 
-		os.Exit(0)
-	} else {
-		log.Printf("%s: run failed", report.Identify())
+// hooksConfig gathers the hooks of the global configuration, followed by those
+// of the workspace
+func hooksConfig() hooks.Config {
+	var config hooks.Config
+	if err := viper.UnmarshalKey("hooks", &config); err != nil {
+		log.Printf("could not read hooks from configuration: %s", err)
+	}
+	if ws != nil {
+		config = config.Merge(ws.Config.Hooks)
+	}
+	return config
+}
 
-		errorReport := *report.ErrorReport()
+// commitConfig gathers the git settings of the global configuration, with
+// those of the workspace on top
+func commitConfig() autocommit.Config {
+	var config autocommit.Config
+	if err := viper.UnmarshalKey("git", &config); err != nil {
+		log.Printf("could not read git settings from configuration: %s", err)
+	}
+	if ws != nil {
+		config = config.Merge(ws.Config.Git)
+	}
+	if doCommit {
+		config.Commit = true
+	}
+	return config
+}
 
-		header := color.New(color.Bold, color.FgRed)
-		bold := color.New(color.Bold)
-		ctx := color.New(color.FgCyan, color.Bold)
+// commitSolution commits an accepted solution at path, along with the project
+// files scaffolded next to it if it is in a workspace. Whatever else is in the
+// problem directory, such as build outputs, is left out.
+func commitSolution(config autocommit.Config, path string, challenge provider.Challenge, lang provider.Lang, stats provider.SubmissionStatistics) error {
+	solution := autocommit.Solution{
+		Path:       path,
+		Provider:   backend,
+		Identity:   challenge.Identify(),
+		Details:    challenge.Details(),
+		Lang:       lang,
+		Statistics: stats,
+	}
 
-		var buf strings.Builder
-		buf.WriteString(header.Sprintf(errorReport.ErrorClass))
-		buf.WriteString(bold.Sprintf(": %s\n", errorReport.ErrorMsg))
-		if errorReport.CtxHeader != "" {
-			buf.WriteString(ctx.Sprintf("  ---> "))
-			buf.WriteString(fmt.Sprintln(errorReport.CtxHeader))
+	if ws != nil {
+		dir, err := ws.ProblemDir(problemVars(solution.Identity, solution.Details, lang))
+		if err != nil {
+			return err
 		}
-		if errorReport.CtxMsg != "" {
-			buf.WriteString(ctx.Sprintf("  | \n"))
-			for _, line := range strings.Split(errorReport.CtxMsg, "\n") {
-				buf.WriteString(ctx.Sprintf("  | "))
-				buf.WriteString(line)
-				buf.WriteString("\n")
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			project, err := workspace.Scaffold(lang, solution.Identity.GetFilterOrDefault("slug"), ws.Config.JavaBuild, "")
+			if err != nil {
+				return err
 			}
-		}
-		output := buf.String()
 
-		fmt.Fprintf(os.Stderr, "\n%s\n", output)
-		os.Exit(1)
+			for name := range project.Files {
+				file := filepath.Join(dir, name)
+				if _, err := os.Stat(file); err == nil {
+					solution.Files = append(solution.Files, file)
+				}
+			}
+		}
 	}
+
+	return autocommit.Commit(config, solution)
 }
 
-var submitCmd = &cobra.Command{
-	Use:   "submit [-p problem-slug | -i problem-id] path",
-	Short: "submit a solution to be judged",
-	Args:  cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		challenge, err := client.GetChallenge(filters)
-		if err != nil {
-			return err
+type submitDocument struct {
+	Challenge   challengeDocument     `json:"challenge"`
+	Lang        string                `json:"lang"`
+	Path        string                `json:"path,omitempty"`
+	Verdict     provider.Verdict      `json:"verdict"`
+	Diagnostics []provider.Diagnostic `json:"diagnostics,omitempty"` // with the line numbers of the file
+}
+
+// printSubmitReport reports the verdict of a submission, returning the exit
+// code of its class
+func printSubmitReport(report provider.SubmissionReport, document submitDocument) int {
+	if report.HasSucceeded() {
+		log.Printf("%s: run succeeded", report.Identify())
+	} else {
+		log.Printf("%s: run failed", report.Identify())
+	}
+
+	if !isTextOutput() {
+		if err := emit(document); err != nil {
+			log.Printf("could not write verdict: %s", err)
 		}
+	} else if report.HasSucceeded() {
+		printStatistics(report.Statistics())
+	} else {
+		printErrorReport(*report.ErrorReport())
 
-		challengeFilters := challenge.Identify()
-		filters.Update(&challengeFilters)
+		// on stdout, for the quickfix lists of editors
+		for _, diagnostic := range document.Diagnostics {
+			fmt.Fprintln(os.Stdout, diagnostic)
+		}
+	}
 
-		var srcFile io.Reader
+	return exitCode(report.Class())
+}
 
-		if srcStr == "" {
-			srcFile = os.Stdin
-		} else {
-			f, err := os.Open(srcStr)
-			if err != nil {
-				return err
-			} else {
-				srcFile = f
-			}
+// submitSolution submits the solution at path (or read from stdin if path is
+// empty) to the problem the filters point to, running hooks around it
+func submitSolution(path string) (provider.SubmissionReport, *submitDocument, error) {
+	challenge, err := client.GetChallenge(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	challengeFilters := challenge.Identify()
+	filters.Update(&challengeFilters)
+
+	var src []byte
+
+	if path == "" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	code, sourceMap, err := provider.DecodeSolution(backend, bytes.NewReader(src))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lang *provider.Lang
+	if langStr == "" {
+		return nil, nil, fmt.Errorf("a --lang must be provided (e.g. rust)")
+	} else {
+		if lang, err = provider.ParseLang(langStr); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		code, err := provider.DecodeSolution(backend, srcFile)
-		if err != nil {
-			return err
+	hookCtx := hooks.Context{
+		Provider: backend,
+		Lang:     *lang,
+		Filters:  filters,
+		Code:     *code,
+	}
+	if path != "" {
+		if hookCtx.Path, err = filepath.Abs(path); err != nil {
+			return nil, nil, err
 		}
+	}
+	if ws != nil {
+		hookCtx.Workspace = ws.Root
+	}
 
-		var lang *provider.Lang
-		if langStr == "" {
-			return fmt.Errorf("a --lang must be provided (e.g. rust)")
-		} else {
-			if lang, err = provider.ParseLang(langStr); err != nil {
-				return err
-			}
+	hookConfig := hooksConfig()
+	if err := hookCtx.RunPreSubmit(hookConfig, doForce); err != nil {
+		return nil, nil, err
+	}
+
+	report, err := client.Submit(filters, *lang, *code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sourcePath := path
+	if sourcePath == "" {
+		sourcePath = "<stdin>"
+	}
+	submitReport := provider.Relocate(report, sourceMap, sourcePath)
+
+	entry := history.NewEntry(history.Submit, backend, challengeFilters, challenge.Details(), *lang)
+	entry.Class = submitReport.Class()
+	recordHistory(entry)
+
+	verdict := provider.NewVerdict(submitReport)
+	if err := hookCtx.RunPostSubmit(hookConfig, verdict); err != nil {
+		log.Printf("could not run post-submit hooks: %s", err)
+	}
+
+	if config := commitConfig(); config.Commit && verdict.Succeeded && path != "" {
+		if err := commitSolution(config, hookCtx.Path, challenge, *lang, submitReport.Statistics()); err != nil {
+			fmt.Fprintf(os.Stderr, "tinycode: could not commit the solution: %s\n", err)
 		}
+	}
+
+	return submitReport, &submitDocument{
+		Challenge:   newChallengeDocument(challengeFilters, challenge.Details()),
+		Lang:        lang.String(),
+		Path:        hookCtx.Path,
+		Verdict:     verdict,
+		Diagnostics: submitReport.Diagnostics,
+	}, nil
+}
 
-		submitReport, err := client.Submit(filters, *lang, *code)
+var submitCmd = &cobra.Command{
+	Use:   "submit [-p problem-slug | -i problem-id] [--force] path",
+	Short: "submit a solution to be judged",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		submitReport, document, err := submitSolution(srcStr)
 		if err != nil {
 			return err
 		}
 
-		printSubmitReportAndExit(submitReport)
-
-		return nil
+		code := printSubmitReport(submitReport, *document)
+		gradeReview()
+		return exitWith(code)
 	},
 }
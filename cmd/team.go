@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/team"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Flags and parameters
+var repoStr string
+var weekStr string
+var weeks int
+var formatStr string
+var doVerified bool
+var doRemote bool
+
+const (
+	MarkdownFormat string = "markdown"
+	HtmlFormat            = "html"
+)
+
+// findSolutions returns the files under root which have a metadata header,
+// skipping hidden directories such as .git
+func findSolutions(root string) ([]team.Solution, error) {
+	var output []team.Solution
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		lang, err := provider.ParseExt(strings.TrimPrefix(filepath.Ext(path), "."))
+		if err != nil {
+			return nil
+		}
+
+		metadata, err := GetMetadata(path)
+		if err != nil || metadata.Backend == "" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		output = append(output, team.Solution{
+			Path:     filepath.ToSlash(rel),
+			Provider: metadata.Backend,
+			Identity: metadata.Filters.Map(),
+			Lang:     lang.String(),
+			Verdict:  team.Unknown,
+		})
+		return nil
+	})
+
+	return output, err
+}
+
+// localVerdicts returns what the local history knows of solutions, keyed by
+// problem and language. They are those of the user's own solutions only.
+func localVerdicts() (map[string]team.Verdict, map[string]string, error) {
+	entries, err := history.Read(history.Path(configPath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verdicts := map[string]team.Verdict{}
+	difficulties := map[string]string{}
+	for _, entry := range entries {
+		if entry.Difficulty != "" {
+			difficulties[entry.Problem()] = strings.ToLower(entry.Difficulty)
+		}
+
+		if entry.Event != history.Submit {
+			continue
+		}
+
+		key := entry.Problem() + "/" + entry.Lang
+		if entry.IsAccepted() {
+			verdicts[key] = team.Accepted
+		} else if verdicts[key] != team.Accepted {
+			verdicts[key] = team.Rejected
+		}
+	}
+
+	return verdicts, difficulties, nil
+}
+
+// fetchDifficulty asks the provider of solution how hard its problem is
+func fetchDifficulty(clients map[string]provider.Provider, solution team.Solution) (string, error) {
+	found, ok := clients[solution.Provider]
+	if !ok {
+		created, err := newClient(solution.Provider)
+		if err != nil {
+			return "", err
+		}
+		if err := authenticate(created, solution.Provider); err != nil {
+			return "", err
+		}
+		clients[solution.Provider] = created
+		found = created
+	}
+
+	identity := provider.Filters{}
+	for name, value := range solution.Identity {
+		if err := identity.AddFilter(name, value); err != nil {
+			return "", err
+		}
+	}
+
+	challenge, err := found.GetChallenge(identity)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(challenge.Details().Difficulty), nil
+}
+
+// reportPeriod returns the weeks covered by the report, the last one
+// containing the day in weekStr
+func reportPeriod() (time.Time, time.Time, error) {
+	day := time.Now()
+	if weekStr != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", weekStr, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --week, expected a date such as 2006-01-02: %s", weekStr)
+		}
+	}
+
+	if weeks < 1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("--weeks must be at least 1")
+	}
+
+	until := team.WeekStart(day).AddDate(0, 0, 7)
+	return until.AddDate(0, 0, -7*weeks), until, nil
+}
+
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "report on the solutions a team keeps in a shared git repository",
+}
+
+var teamReportCmd = &cobra.Command{
+	Use:     "report [--repo PATH] [--week DATE] [--weeks N] [--format FORMAT] [--verified] [--remote]",
+	Short:   "build a weekly leaderboard out of the solutions added to a git repository",
+	Example: `  tinycode team report --repo ~/team-solutions --format html > leaderboard.html`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if formatStr != MarkdownFormat && formatStr != HtmlFormat {
+			return fmt.Errorf("invalid --format: %s (expected markdown or html)", formatStr)
+		}
+
+		since, until, err := reportPeriod()
+		if err != nil {
+			return err
+		}
+
+		root, err := team.Toplevel(repoStr)
+		if err != nil {
+			return err
+		}
+
+		commits, err := team.Log(root)
+		if err != nil {
+			return err
+		}
+		histories := team.Histories(commits)
+
+		found, err := findSolutions(root)
+		if err != nil {
+			return err
+		}
+
+		verdicts, difficulties, err := localVerdicts()
+		if err != nil {
+			return err
+		}
+
+		self, err := team.Self(root)
+		if err != nil {
+			log.Printf("not using the verdicts of the local history, as the author of its solutions is unknown: %s", err)
+		}
+
+		clients := map[string]provider.Provider{}
+
+		var solutions []team.Solution
+		for _, solution := range found {
+			record, ok := histories[solution.Path]
+			if !ok {
+				log.Printf("%s was never committed, skipping", solution.Path)
+				continue
+			}
+
+			solution.Author = record.Added.Author
+			solution.Time = record.Added.Time
+
+			// a verdict recorded in the repository is shared by everyone, so it
+			// takes precedence over the local history, which only knows of the
+			// user's own solutions
+			solution.Verdict = record.Verdict
+			if solution.Verdict == team.Unknown && self.Email != "" && strings.EqualFold(solution.Author.Email, self.Email) {
+				if verdict, ok := verdicts[solution.Problem()+"/"+solution.Lang]; ok {
+					solution.Verdict = verdict
+				}
+			}
+
+			solution.Difficulty = difficulties[solution.Problem()]
+			if solution.Difficulty == "" && doRemote && !solution.Time.Before(since) && solution.Time.Before(until) {
+				difficulty, err := fetchDifficulty(clients, solution)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "tinycode: could not fetch the difficulty of %s: %s\n", solution.Path, err)
+				}
+				solution.Difficulty = difficulty
+				difficulties[solution.Problem()] = difficulty
+			}
+
+			solutions = append(solutions, solution)
+		}
+
+		report := team.NewReport(root, solutions, since, until, doVerified)
+
+		if !isTextOutput() {
+			return emit(report)
+		}
+
+		if formatStr == HtmlFormat {
+			return team.RenderHtml(os.Stdout, report)
+		}
+		return team.RenderMarkdown(os.Stdout, report)
+	},
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/runner"
+	"github.com/brokad/tinycode/sandbox"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func sampleReport(sample runner.Sample, verdict *sandbox.Verdict) *provider.ErrorReport {
+	input := strings.TrimRight(sample.Input, "\n")
+	header := fmt.Sprintf("on input %s:", filepath.Base(sample.Path))
+
+	if !verdict.HasSucceeded() {
+		report := provider.NewErrorReport(
+			verdict.Status.String(),
+			fmt.Sprintf("solution %s", verdict.Describe()),
+			header,
+			fmt.Sprintf("%s\n\nstderr:\n%s", input, verdict.Stderr),
+		)
+		return &report
+	}
+
+	if sample.Expected != nil && !runner.SameOutput(*sample.Expected, verdict.Stdout) {
+		report := provider.NewErrorReport(
+			"wrong answer",
+			"output differs from the expected one",
+			header,
+			fmt.Sprintf("%s\n\nexpected:\n%s\n\ngot:\n%s\n", input, strings.TrimRight(*sample.Expected, "\n"), strings.TrimRight(verdict.Stdout, "\n")),
+		)
+		return &report
+	}
+
+	return nil
+}
+
+type sampleDocument struct {
+	Input    string                `json:"input"`
+	Class    provider.VerdictClass `json:"class"`
+	Checked  bool                  `json:"checked"` // false if there was no expected output to compare with
+	Exit     string                `json:"exit"`
+	CPUTime  int64                 `json:"cpu_time_ms"`
+	WallTime int64                 `json:"wall_time_ms"`
+	Memory   uint64                `json:"memory"`
+	Stdout   string                `json:"stdout"`
+	Stderr   string                `json:"stderr,omitempty"`
+	Expected *string               `json:"expected,omitempty"`
+}
+
+type testDocument struct {
+	Path    string           `json:"path"`
+	Passed  bool             `json:"passed"`
+	Samples []sampleDocument `json:"samples"`
+}
+
+func (document *testDocument) failures() int {
+	failures := 0
+	for _, sample := range document.Samples {
+		if sample.Class != provider.Accepted {
+			failures++
+		}
+	}
+	return failures
+}
+
+// failure is the class of the first sample which failed, Accepted if none did
+func (document *testDocument) failure() provider.VerdictClass {
+	for _, sample := range document.Samples {
+		if sample.Class != provider.Accepted {
+			return sample.Class
+		}
+	}
+	return provider.Accepted
+}
+
+func newSampleDocument(sample runner.Sample, verdict *sandbox.Verdict) sampleDocument {
+	sameOutput := sample.Expected == nil || runner.SameOutput(*sample.Expected, verdict.Stdout)
+	return sampleDocument{
+		Input:    sample.Path,
+		Class:    localClass(verdict, sameOutput),
+		Checked:  sample.Expected != nil,
+		Exit:     verdict.Describe(),
+		CPUTime:  verdict.CPUTime.Milliseconds(),
+		WallTime: verdict.WallTime.Milliseconds(),
+		Memory:   verdict.Memory,
+		Stdout:   verdict.Stdout,
+		Stderr:   verdict.Stderr,
+		Expected: sample.Expected,
+	}
+}
+
+// runSamples builds the solution at path and runs it on each of its samples,
+// calling onResult as they complete
+func runSamples(path string, lang *provider.Lang, onResult func(runner.Sample, *sandbox.Verdict, sampleDocument)) (*testDocument, error) {
+	root := ""
+	if ws != nil {
+		root = ws.Root
+	}
+
+	samples, err := runner.FindSamples(path, root)
+	if err != nil {
+		return nil, err
+	} else if len(samples) == 0 {
+		// a query on the tables of its schema is a sample of its own
+		schema := runner.SchemaPath(path)
+		if schema == "" {
+			return nil, fmt.Errorf("no sample inputs (*.in files) found for %s", path)
+		}
+		samples = []runner.Sample{{Path: schema}}
+	}
+
+	solution, err := buildProgram(path, lang)
+	if err != nil {
+		return nil, err
+	}
+	defer solution.Close()
+
+	document := testDocument{Path: path, Passed: true}
+	for _, sample := range samples {
+		verdict, err := solution.Run(context.Background(), sample.Input)
+		if err != nil {
+			return nil, err
+		}
+
+		result := newSampleDocument(sample, verdict)
+		document.Samples = append(document.Samples, result)
+		if result.Class != provider.Accepted {
+			document.Passed = false
+		}
+
+		onResult(sample, verdict, result)
+	}
+
+	return &document, nil
+}
+
+var testCmd = &cobra.Command{
+	Use:     "test [-l LANG] [--timeout T] [--memory MB] PATH",
+	Short:   "run a solution locally on the sample inputs found next to it",
+	Example: `  tinycode test two-sum.cpp`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var lang *provider.Lang
+		if langStr != "" {
+			parsed, err := provider.ParseLang(langStr)
+			if err != nil {
+				return err
+			}
+			lang = parsed
+		}
+
+		ok := color.New(color.Bold, color.FgGreen)
+		failed := color.New(color.Bold, color.FgRed)
+
+		document, err := runSamples(srcStr, lang, func(sample runner.Sample, verdict *sandbox.Verdict, result sampleDocument) {
+			if outputStr == NdjsonOutput {
+				if err := emit(result); err != nil {
+					log.Printf("could not write sample result: %s", err)
+				}
+				return
+			} else if !isTextOutput() {
+				return
+			}
+
+			name := filepath.Base(sample.Path)
+			elapsed := verdict.WallTime.Round(time.Millisecond)
+
+			if report := sampleReport(sample, verdict); report != nil {
+				fmt.Fprintf(os.Stderr, "    %s %s (%s)\n", failed.Sprintf("Failed"), name, elapsed)
+				printErrorReport(*report)
+			} else if sample.Expected == nil {
+				fmt.Fprintf(os.Stderr, "    %s %s (%s), no expected output to compare with:\n", ok.Sprintf("Ran"), name, elapsed)
+				for _, line := range strings.Split(strings.TrimRight(verdict.Stdout, "\n"), "\n") {
+					fmt.Fprintf(os.Stderr, "      %s\n", line)
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "    %s %s (%s)\n", ok.Sprintf("Passed"), name, elapsed)
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		if outputStr == JsonOutput {
+			if err := emit(document); err != nil {
+				return err
+			}
+		}
+
+		if !document.Passed {
+			if isTextOutput() {
+				fmt.Fprintf(os.Stderr, "\n%d out of %d samples failed\n", document.failures(), len(document.Samples))
+			}
+			return exitWith(exitCode(document.failure()))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/hackerrank"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"net/url"
+	"strings"
+)
+
+// Flags and parameters
+var doRefresh bool
+
+type tracksDocument struct {
+	Tracks []hackerrank.TrackNode `json:"tracks"`
+}
+
+// completionTaxonomy is the taxonomy of HackerRank, for completing flags,
+// nil if the provider is another one or the taxonomy cannot be fetched
+func completionTaxonomy() *hackerrank.Taxonomy {
+	if backend != "" && backend != HackerRank {
+		return nil
+	}
+
+	base, _ := url.Parse(HackerRankUrl)
+	hrClient := hackerrank.NewClient(base)
+	hrClient.TaxonomyPath = hackerrank.TaxonomyPath(configPath)
+
+	taxonomy, err := hrClient.Taxonomy()
+	if err != nil {
+		return nil
+	}
+	return taxonomy
+}
+
+func completeTracks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if taxonomy := completionTaxonomy(); taxonomy != nil {
+		return taxonomy.TrackSlugs(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeList completes the last item of a comma-separated list of slugs
+func completeList(toComplete string, slugs []string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i != -1 {
+		prefix = toComplete[:i+1]
+	}
+
+	var completions []string
+	for _, slug := range slugs {
+		completions = append(completions, prefix+slug)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func completeSubdomains(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if taxonomy := completionTaxonomy(); taxonomy != nil {
+		return completeList(toComplete, taxonomy.Subdomains(trackStr))
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeSkills(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if taxonomy := completionTaxonomy(); taxonomy != nil {
+		return completeList(toComplete, taxonomy.Skills(trackStr))
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completePlaylists(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	playlists := []string{hackerrank.InterviewPreparationKit, hackerrank.ThirtyDaysOfCode, hackerrank.TenDaysOfJavascript}
+	return playlists, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerSearchCompletions completes the --track, --tags, --skills and
+// --playlist flags of cmd which it has, from the taxonomy of HackerRank
+func registerSearchCompletions(cmd *cobra.Command) {
+	completions := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"track":    completeTracks,
+		"tags":     completeSubdomains,
+		"skills":   completeSkills,
+		"playlist": completePlaylists,
+	}
+
+	for name, completion := range completions {
+		if cmd.Flags().Lookup(name) != nil {
+			cmd.RegisterFlagCompletionFunc(name, completion)
+		}
+	}
+}
+
+var tracksCmd = &cobra.Command{
+	Use:   "tracks [--track TRACK] [--refresh]",
+	Short: "show the tracks of HackerRank, with their subdomains (-t/--tags) and skills (--skills)",
+	Long: `The tracks are fetched from HackerRank once a week, and kept in the
+configuration directory in the meantime.`,
+	Example: `  tinycode tracks --track algorithms`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hrClient, ok := client.(*hackerrank.Client)
+		if !ok {
+			return fmt.Errorf("tracks are only available on hackerrank")
+		}
+
+		var taxonomy *hackerrank.Taxonomy
+		var err error
+		if doRefresh {
+			if taxonomy, err = hrClient.FetchTaxonomy(); err == nil {
+				err = taxonomy.Save(hrClient.TaxonomyPath)
+			}
+		} else {
+			taxonomy, err = hrClient.Taxonomy()
+		}
+		if err != nil {
+			return err
+		}
+
+		tracks := taxonomy.Tracks
+		if trackStr != "" {
+			track := taxonomy.Track(trackStr)
+			if track == nil {
+				return fmt.Errorf("unknown track: %s, must be one of: %s", trackStr, strings.Join(taxonomy.TrackSlugs(), ", "))
+			}
+			tracks = []hackerrank.TrackNode{*track}
+		}
+
+		if !isTextOutput() {
+			return emit(tracksDocument{Tracks: tracks})
+		}
+
+		bold := color.New(color.Bold)
+		faint := color.New(color.Faint)
+		for _, track := range tracks {
+			fmt.Printf("%s %s\n", bold.Sprint(track.Slug), faint.Sprint(track.Name))
+			for _, subdomain := range track.Subdomains {
+				fmt.Printf("  %s %s\n", subdomain.Slug, faint.Sprint(subdomain.Name))
+			}
+			if len(track.Skills) != 0 {
+				fmt.Println("  skills:")
+				for _, skill := range track.Skills {
+					fmt.Printf("    %s %s\n", skill.Slug, faint.Sprint(skill.Name))
+				}
+			}
+		}
+
+		return nil
+	},
+}
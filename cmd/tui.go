@@ -0,0 +1,780 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/runner"
+	"github.com/brokad/tinycode/sandbox"
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tuiPageSize is the number of problems fetched at once, more being fetched
+// when scrolling past the last one
+const tuiPageSize = 50
+
+// tuiResultsHeight is the number of rows of the results panel, its title
+// included
+const tuiResultsHeight = 10
+
+var tuiDifficulties = []string{"", "easy", "medium", "hard"}
+var tuiStatuses = []string{"", "todo", "attempted", "solved"}
+
+// tuiInput is a line being edited in the status bar
+type tuiInput struct {
+	label string
+	value []rune
+	apply func(string)
+}
+
+type tui struct {
+	screen tcell.Screen
+	stdout *os.File // of the terminal, stdout itself being captured
+	stderr *os.File
+
+	base       map[string]string // filters which apply to every search, e.g. the contest
+	difficulty string
+	status     string
+	tags       string
+	track      string
+
+	summaries []provider.ChallengeSummary
+	exhausted bool // no more problems to fetch
+	selected  int
+	listTop   int
+
+	prompts   map[string][]string // by slug
+	promptTop int
+	paths     map[string]string // of the solutions checked out, by slug
+
+	results []string
+	message string
+	busy    bool
+	input   *tuiInput
+}
+
+func cycle(values []string, current string) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+func orAny(value string) string {
+	if value == "" {
+		return "any"
+	}
+	return value
+}
+
+func (t *tui) searchFilters() (provider.Filters, error) {
+	var output provider.Filters
+	for name, value := range t.base {
+		if err := output.AddFilter(name, value); err != nil {
+			return output, err
+		}
+	}
+
+	search := map[string]string{
+		"difficulty": t.difficulty,
+		"status":     t.status,
+		"tags":       t.tags,
+		"track":      t.track,
+	}
+	for name, value := range search {
+		if value == "" {
+			continue
+		}
+		if err := output.AddFilter(name, value); err != nil {
+			return output, err
+		}
+	}
+
+	return output, nil
+}
+
+// challengeFilters are the filters pointing to the challenge of summary
+func (t *tui) challengeFilters(summary provider.ChallengeSummary) provider.Filters {
+	var output provider.Filters
+	for name, value := range t.base {
+		_ = output.AddFilter(name, value)
+	}
+	output.Update(&summary.Filters)
+	return output
+}
+
+func (t *tui) current() *provider.ChallengeSummary {
+	if t.selected < 0 || t.selected >= len(t.summaries) {
+		return nil
+	}
+	return &t.summaries[t.selected]
+}
+
+func (t *tui) currentSlug() string {
+	if summary := t.current(); summary != nil {
+		return summary.Filters.GetFilterOrDefault("slug")
+	}
+	return ""
+}
+
+func (t *tui) addResults(s string) {
+	t.results = append(t.results, strings.Split(strings.TrimRight(s, "\n"), "\n")...)
+}
+
+// async runs work in the background, one at a time since it uses the same
+// state as commands do. The function work returns is then run in the event
+// loop, where the interface can be updated.
+func (t *tui) async(message string, work func() func()) {
+	if t.busy {
+		t.message = "busy, please wait..."
+		return
+	}
+	t.busy = true
+	t.message = message
+
+	go func() {
+		done := work()
+		t.screen.PostEvent(tcell.NewEventInterrupt(func() {
+			t.busy = false
+			t.message = ""
+			done()
+			t.fetchPrompt()
+		}))
+	}()
+}
+
+// post runs fn in the event loop, from a background task
+func (t *tui) post(fn func()) {
+	t.screen.PostEvent(tcell.NewEventInterrupt(fn))
+}
+
+func (t *tui) fail(err error) func() {
+	return func() {
+		t.addResults(fmt.Sprintf("error: %s", err))
+	}
+}
+
+// search lists problems again, from the first one
+func (t *tui) search() {
+	if t.busy {
+		t.message = "busy, please wait..."
+		return
+	}
+
+	t.summaries = nil
+	t.exhausted = false
+	t.selected = 0
+	t.listTop = 0
+	t.promptTop = 0
+	t.fetchMore()
+}
+
+func (t *tui) fetchMore() {
+	if t.exhausted {
+		return
+	}
+
+	searchFilters, err := t.searchFilters()
+	if err != nil {
+		t.fail(err)()
+		return
+	}
+
+	offset := uint64(len(t.summaries))
+	t.async("loading problems...", func() func() {
+		summaries, err := client.List(searchFilters, offset, tuiPageSize)
+		if err != nil {
+			return t.fail(err)
+		}
+		return func() {
+			t.summaries = append(t.summaries, summaries...)
+			t.exhausted = len(summaries) < tuiPageSize
+		}
+	})
+}
+
+// fetchPrompt fetches the prompt of the selected problem, if not already known
+func (t *tui) fetchPrompt() {
+	summary := t.current()
+	if summary == nil || t.busy {
+		return
+	}
+
+	slug := t.currentSlug()
+	if _, ok := t.prompts[slug]; ok {
+		return
+	}
+
+	challengeFilters := t.challengeFilters(*summary)
+	t.async(fmt.Sprintf("loading %s...", slug), func() func() {
+		challenge, err := client.GetChallenge(challengeFilters)
+		if err != nil {
+			return func() {
+				t.prompts[slug] = []string{fmt.Sprintf("could not load the prompt: %s", err)}
+			}
+		}
+
+		prompt := challenge.Prompt()
+
+		// some providers only give the prompt as a file, in which case it is
+		// converted as well
+		if files, err := challenge.Files(); err == nil {
+			for name, content := range files {
+				if filepath.Ext(name) == ".html" {
+					prompt = provider.HtmlToText(content)
+				}
+			}
+		}
+
+		details := challenge.Details()
+		header := fmt.Sprintf("%s (%s)\n\n", details.Title, details.Difficulty)
+
+		return func() {
+			t.prompts[slug] = strings.Split(header+strings.TrimSpace(prompt), "\n")
+		}
+	})
+}
+
+func (t *tui) move(delta int) {
+	if len(t.summaries) == 0 {
+		return
+	}
+
+	t.selected += delta
+	if t.selected < 0 {
+		t.selected = 0
+	}
+	if t.selected >= len(t.summaries) {
+		t.selected = len(t.summaries) - 1
+	}
+	t.promptTop = 0
+
+	if t.selected == len(t.summaries)-1 {
+		t.fetchMore()
+	}
+	t.fetchPrompt()
+}
+
+func (t *tui) checkout() {
+	summary := t.current()
+	if summary == nil {
+		return
+	}
+
+	slug := t.currentSlug()
+	challengeFilters := t.challengeFilters(*summary)
+	t.async(fmt.Sprintf("checking out %s...", slug), func() func() {
+		filters = challengeFilters
+		srcStr = "."
+		if ws != nil {
+			srcStr = ws.Root
+		}
+
+		document, _, err := checkoutChallenge()
+		if err != nil {
+			return t.fail(err)
+		}
+
+		return func() {
+			t.paths[slug] = document.Path
+			t.addResults(fmt.Sprintf("checked out %s to %s", slug, document.Path))
+		}
+	})
+}
+
+// solution is the path of the solution of the selected problem, if checked out
+func (t *tui) solution() (string, bool) {
+	path, ok := t.paths[t.currentSlug()]
+	if !ok {
+		t.message = "check out the problem first (c)"
+	}
+	return path, ok
+}
+
+func (t *tui) edit() {
+	path, ok := t.solution()
+	if !ok {
+		return
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		t.message = "no $EDITOR set, try `export EDITOR=emacs`"
+		return
+	}
+
+	if err := t.screen.Suspend(); err != nil {
+		t.fail(err)()
+		return
+	}
+
+	editorCmdArgs := append(strings.Split(editor, " "), path)
+	editorCmd := exec.Command(editorCmdArgs[0], editorCmdArgs[1:]...)
+	editorCmd.Stdout = t.stdout
+	editorCmd.Stderr = t.stderr
+	editorCmd.Stdin = os.Stdin
+	err := editorCmd.Run()
+
+	if err := t.screen.Resume(); err != nil {
+		log.Printf("could not resume the screen: %s", err)
+	}
+	if err != nil {
+		t.fail(err)()
+	}
+}
+
+func (t *tui) test() {
+	path, ok := t.solution()
+	if !ok {
+		return
+	}
+
+	var lang *provider.Lang
+	if langStr != "" {
+		parsed, err := provider.ParseLang(langStr)
+		if err != nil {
+			t.fail(err)()
+			return
+		}
+		lang = parsed
+	}
+
+	t.async("running samples...", func() func() {
+		t.post(func() { t.addResults(fmt.Sprintf("testing %s", path)) })
+
+		document, err := runSamples(path, lang, func(sample runner.Sample, verdict *sandbox.Verdict, result sampleDocument) {
+			name := filepath.Base(sample.Path)
+			elapsed := verdict.WallTime.Round(time.Millisecond)
+
+			report := sampleReport(sample, verdict)
+			t.post(func() {
+				if report != nil {
+					t.addResults(fmt.Sprintf("    Failed %s (%s)", name, elapsed))
+					t.addResults(formatErrorReport(*report))
+				} else {
+					t.addResults(fmt.Sprintf("    Passed %s (%s)", name, elapsed))
+				}
+			})
+		})
+		if err != nil {
+			return t.fail(err)
+		}
+
+		return func() {
+			t.addResults(fmt.Sprintf("%d out of %d samples failed", document.failures(), len(document.Samples)))
+		}
+	})
+}
+
+func (t *tui) submit() {
+	path, ok := t.solution()
+	if !ok {
+		return
+	}
+
+	slug := t.currentSlug()
+	challengeFilters := t.challengeFilters(*t.current())
+	t.async("submitting...", func() func() {
+		t.post(func() { t.addResults(fmt.Sprintf("submitting %s", path)) })
+
+		filters = challengeFilters
+		srcStr = path
+		doForce = false
+
+		if observable, ok := client.(provider.Observable); ok {
+			observable.OnProgress(func(progress provider.Progress) {
+				t.post(func() {
+					t.message = fmt.Sprintf("judging... %s (%dms)", progress.State, progress.Elapsed)
+				})
+			})
+			defer observable.OnProgress(nil)
+		}
+
+		report, _, err := submitSolution(path)
+		if err != nil {
+			return t.fail(err)
+		}
+
+		return func() {
+			status := provider.Attempted
+			if report.HasSucceeded() {
+				t.addResults(formatStatistics(report.Statistics()))
+				status = provider.Solved
+			} else {
+				t.addResults(formatErrorReport(*report.ErrorReport()))
+			}
+
+			for i := range t.summaries {
+				summary := &t.summaries[i]
+				if summary.Filters.GetFilterOrDefault("slug") == slug && summary.Status != provider.Solved {
+					summary.Status = status
+				}
+			}
+		}
+	})
+}
+
+func (t *tui) edited(label string, value string, apply func(string)) {
+	t.input = &tuiInput{label: label, value: []rune(value), apply: apply}
+}
+
+func (t *tui) inputKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		input := t.input
+		t.input = nil
+		input.apply(strings.TrimSpace(string(input.value)))
+	case tcell.KeyEscape:
+		t.input = nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(t.input.value) > 0 {
+			t.input.value = t.input.value[:len(t.input.value)-1]
+		}
+	case tcell.KeyRune:
+		t.input.value = append(t.input.value, ev.Rune())
+	}
+}
+
+// key handles a key press, returning true to quit
+func (t *tui) key(ev *tcell.EventKey) bool {
+	if t.input != nil {
+		t.inputKey(ev)
+		return false
+	}
+
+	if !t.busy {
+		t.message = ""
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC, tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		t.move(-1)
+	case tcell.KeyDown:
+		t.move(1)
+	case tcell.KeyPgUp:
+		t.scrollPrompt(-10)
+	case tcell.KeyPgDn:
+		t.scrollPrompt(10)
+	case tcell.KeyEnter:
+		t.checkout()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			t.move(-1)
+		case 'j':
+			t.move(1)
+		case 'K':
+			t.scrollPrompt(-1)
+		case 'J':
+			t.scrollPrompt(1)
+		case 'c':
+			t.checkout()
+		case 'e':
+			t.edit()
+		case 't':
+			t.test()
+		case 's':
+			t.submit()
+		case 'x':
+			t.results = nil
+		case 'd':
+			t.difficulty = cycle(tuiDifficulties, t.difficulty)
+			t.search()
+		case 'a':
+			t.status = cycle(tuiStatuses, t.status)
+			t.search()
+		case '/':
+			t.edited("tags", t.tags, func(value string) {
+				t.tags = value
+				t.search()
+			})
+		case 'r':
+			t.edited("track", t.track, func(value string) {
+				t.track = value
+				t.search()
+			})
+		}
+	}
+
+	return false
+}
+
+func (t *tui) scrollPrompt(delta int) {
+	t.promptTop += delta
+	if lines := len(t.prompts[t.currentSlug()]); t.promptTop >= lines {
+		t.promptTop = lines - 1
+	}
+	if t.promptTop < 0 {
+		t.promptTop = 0
+	}
+}
+
+// text draws s from x to at most x+width, returning where it stopped
+func (t *tui) text(x int, y int, width int, style tcell.Style, s string) int {
+	end := x + width
+	for _, r := range s {
+		if x >= end {
+			break
+		}
+		if r == '\t' {
+			r = ' '
+		}
+		t.screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+	return x
+}
+
+func (t *tui) fill(x int, y int, width int, style tcell.Style) {
+	for i := 0; i < width; i++ {
+		t.screen.SetContent(x+i, y, ' ', nil, style)
+	}
+}
+
+// wrap splits lines so that none is wider than width
+func wrap(lines []string, width int) []string {
+	var output []string
+	for _, line := range lines {
+		runes := []rune(line)
+		for len(runes) > width {
+			output = append(output, string(runes[:width]))
+			runes = runes[width:]
+		}
+		output = append(output, string(runes))
+	}
+	return output
+}
+
+func difficultyStyle(difficulty string) tcell.Style {
+	switch strings.ToLower(difficulty) {
+	case "easy":
+		return tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	case "medium":
+		return tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	case "hard", "expert", "advanced":
+		return tcell.StyleDefault.Foreground(tcell.ColorRed)
+	default:
+		return tcell.StyleDefault
+	}
+}
+
+func statusMark(status string) string {
+	switch status {
+	case provider.Solved:
+		return "✓"
+	case provider.Attempted:
+		return "~"
+	default:
+		return " "
+	}
+}
+
+func (t *tui) draw() {
+	t.screen.Clear()
+	width, height := t.screen.Size()
+
+	bar := tcell.StyleDefault.Reverse(true)
+	bold := tcell.StyleDefault.Bold(true)
+
+	// header, with the filters of the search
+	t.fill(0, 0, width, bar)
+	header := fmt.Sprintf(
+		" tinycode: %s │ difficulty: %s │ status: %s │ tags: %s │ track: %s",
+		backend, orAny(t.difficulty), orAny(t.status), orAny(t.tags), orAny(t.track),
+	)
+	t.text(0, 0, width, bar, header)
+
+	resultsTop := height - 1 - tuiResultsHeight
+	paneHeight := resultsTop - 1
+	if paneHeight < 1 {
+		paneHeight = 1
+		resultsTop = 2
+	}
+
+	listWidth := width * 2 / 5
+	if listWidth > 50 {
+		listWidth = 50
+	}
+
+	// list of problems
+	if t.selected < t.listTop {
+		t.listTop = t.selected
+	}
+	if t.selected >= t.listTop+paneHeight {
+		t.listTop = t.selected - paneHeight + 1
+	}
+	for row := 0; row < paneHeight; row++ {
+		i := t.listTop + row
+		if i >= len(t.summaries) {
+			break
+		}
+
+		summary := t.summaries[i]
+		style := tcell.StyleDefault
+		if i == t.selected {
+			style = style.Reverse(true)
+			t.fill(0, row+1, listWidth, style)
+		}
+
+		x := t.text(0, row+1, listWidth, style, fmt.Sprintf(" %s ", statusMark(summary.Status)))
+		difficulty := fmt.Sprintf("%-7s", strings.ToLower(summary.Details.Difficulty))
+		x = t.text(x, row+1, listWidth-x, difficultyStyle(summary.Details.Difficulty).Reverse(i == t.selected), difficulty)
+		t.text(x, row+1, listWidth-x, style, fmt.Sprintf(" %s", summary.Details.Title))
+	}
+	if len(t.summaries) == 0 && !t.busy {
+		t.text(1, 1, listWidth-1, tcell.StyleDefault, "no problems found")
+	}
+
+	// prompt of the selected problem
+	for row := 0; row < paneHeight; row++ {
+		t.screen.SetContent(listWidth, row+1, '│', nil, tcell.StyleDefault)
+	}
+	promptWidth := width - listWidth - 2
+	if promptWidth > 0 {
+		prompt := wrap(t.prompts[t.currentSlug()], promptWidth)
+		for row := 0; row < paneHeight && t.promptTop+row < len(prompt); row++ {
+			style := tcell.StyleDefault
+			if t.promptTop+row == 0 {
+				style = bold
+			}
+			t.text(listWidth+2, row+1, promptWidth, style, prompt[t.promptTop+row])
+		}
+	}
+
+	// results of checkouts, tests and submissions
+	for x := 0; x < width; x++ {
+		t.screen.SetContent(x, resultsTop, '─', nil, tcell.StyleDefault)
+	}
+	t.text(1, resultsTop, width-1, bold, " results ")
+	results := wrap(t.results, width)
+	rows := height - 1 - resultsTop - 1
+	if len(results) > rows && rows > 0 {
+		results = results[len(results)-rows:]
+	}
+	for row, line := range results {
+		t.text(0, resultsTop+1+row, width, tcell.StyleDefault, line)
+	}
+
+	// status bar, or the line being edited
+	t.fill(0, height-1, width, bar)
+	if t.input != nil {
+		x := t.text(0, height-1, width, bar, fmt.Sprintf(" %s: %s", t.input.label, string(t.input.value)))
+		t.screen.ShowCursor(x, height-1)
+	} else {
+		t.screen.HideCursor()
+		status := " ↑↓ move · c checkout · e edit · t test · s submit · d difficulty · a status · / tags · r track · q quit"
+		if t.message != "" {
+			status = fmt.Sprintf(" %s", t.message)
+		}
+		t.text(0, height-1, width, bar, status)
+	}
+
+	t.screen.Show()
+}
+
+// capture redirects stdout and stderr (including the output of hooks) to the
+// results panel, returning a function to restore them
+func (t *tui) capture() (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	t.stdout, t.stderr = os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = writer, writer
+	if debug {
+		log.SetOutput(writer)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := scanner.Text()
+			t.post(func() { t.addResults(line) })
+		}
+	}()
+
+	return func() {
+		os.Stdout, os.Stderr = t.stdout, t.stderr
+		if debug {
+			log.SetOutput(t.stderr)
+		}
+		writer.Close()
+	}, nil
+}
+
+func (t *tui) run() error {
+	if err := t.screen.Init(); err != nil {
+		return err
+	}
+	defer t.screen.Fini()
+
+	restore, err := t.capture()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	t.search()
+
+	for {
+		t.draw()
+
+		switch ev := t.screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			t.screen.Sync()
+		case *tcell.EventKey:
+			if t.key(ev) {
+				return nil
+			}
+		case *tcell.EventInterrupt:
+			ev.Data().(func())()
+		}
+	}
+}
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui [-l LANG] [-d DIFFICULTY] [--status STATUS] [-t TAGS] [--track TRACK] [--contest CONTEST]",
+	Short:   "browse, solve and submit problems in a terminal interface",
+	Example: `  tinycode tui -p leetcode -l rust --status todo`,
+	Args:    cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isTextOutput() {
+			return fmt.Errorf("tui only supports text output")
+		}
+
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			return err
+		}
+
+		// the results panel is plain text
+		color.NoColor = true
+
+		t := tui{
+			screen:     screen,
+			base:       filters.Map(),
+			difficulty: difficultyStr,
+			status:     statusStr,
+			tags:       tagsStr,
+			track:      trackStr,
+			prompts:    map[string][]string{},
+			paths:      map[string]string{},
+		}
+		return t.run()
+	},
+}
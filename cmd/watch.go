@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/brokad/tinycode/console"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/runner"
+	"github.com/brokad/tinycode/sandbox"
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// submitMarker is looked for anywhere in a watched solution, e.g. in a
+// `// tinycode: submit` comment
+var submitMarker = regexp.MustCompile(`tinycode:\s*submit\b`)
+
+// debounceDelay is how long to wait for a burst of file events (as produced by
+// most editors when saving) to settle
+const debounceDelay = 150 * time.Millisecond
+
+type watcher struct {
+	path   string
+	lang   *provider.Lang
+	region string // the last submit region which was tested
+	marked bool   // whether the submit marker was present when last read
+}
+
+// read returns the submit region of the solution, and whether it contains the
+// submit marker
+func (w *watcher) read() (string, bool, error) {
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return "", false, err
+	}
+
+	code, _, err := provider.DecodeSolution(backend, bytes.NewReader(content))
+	if err != nil {
+		return "", false, err
+	}
+
+	return *code, submitMarker.Match(content), nil
+}
+
+func (w *watcher) printStatus(c *color.Color, format string, args ...interface{}) {
+	if isTextOutput() {
+		timestamp := time.Now().Format("15:04:05")
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", timestamp, c.Sprintf(format, args...))
+	}
+}
+
+func (w *watcher) test() {
+	var failure *provider.ErrorReport
+	document, err := runSamples(w.path, w.lang, func(sample runner.Sample, verdict *sandbox.Verdict, result sampleDocument) {
+		if failure == nil {
+			failure = sampleReport(sample, verdict)
+		}
+	})
+
+	if err != nil {
+		w.printStatus(color.New(color.Bold, color.FgRed), "✗ could not run the samples")
+		if isTextOutput() {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		} else {
+			emit(errorDocument{Error: err.Error()})
+		}
+		return
+	}
+
+	if !isTextOutput() {
+		if err := emit(document); err != nil {
+			log.Printf("could not write test results: %s", err)
+		}
+		return
+	}
+
+	total := len(document.Samples)
+	if failures := document.failures(); failures == 0 {
+		w.printStatus(color.New(color.Bold, color.FgGreen), "✓ %d/%d samples passed", total, total)
+	} else {
+		w.printStatus(color.New(color.Bold, color.FgRed), "✗ %d/%d samples failed", failures, total)
+		if failure != nil {
+			printErrorReport(*failure)
+		}
+	}
+}
+
+func (w *watcher) submit() {
+	w.printStatus(color.New(color.Bold), "submitting %s", w.path)
+
+	report, document, err := submitSolution(w.path)
+	if err != nil {
+		w.printStatus(color.New(color.Bold, color.FgRed), "✗ could not submit: %s", err)
+		return
+	}
+
+	printSubmitReport(report, *document)
+	if isTextOutput() {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// update re-reads the solution, testing it again if its submit region changed
+// (or if force is set), and submitting it if the submit marker was just added
+func (w *watcher) update(force bool) {
+	region, marked, err := w.read()
+	if err != nil {
+		w.printStatus(color.New(color.Bold, color.FgRed), "✗ could not read %s: %s", w.path, err)
+		return
+	}
+
+	if force || region != w.region {
+		w.region = region
+		w.test()
+	}
+
+	if marked && !w.marked {
+		w.submit()
+	}
+	w.marked = marked
+}
+
+// readKeys forwards keystrokes from stdin, if it is a terminal which can be
+// switched to cbreak mode. The returned function restores the terminal.
+func readKeys() (<-chan byte, func()) {
+	keys := make(chan byte)
+
+	if !console.IsTerminal(os.Stdin) {
+		return keys, func() {}
+	}
+
+	restore, err := console.Cbreak(os.Stdin)
+	if err != nil {
+		log.Printf("could not read keystrokes: %s", err)
+		return keys, func() {}
+	}
+
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if n, err := os.Stdin.Read(buf); err != nil {
+				return
+			} else if n == 1 {
+				keys <- buf[0]
+			}
+		}
+	}()
+
+	return keys, func() { restore() }
+}
+
+func isSampleFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".in", ".out", ".ans":
+		return true
+	default:
+		return false
+	}
+}
+
+var watchCmd = &cobra.Command{
+	Use:     "watch [-l LANG] [--timeout T] [--memory MB] [--force] PATH",
+	Short:   "re-run the local tests of a solution whenever it changes, and submit it on demand",
+	Example: `  tinycode watch two-sum.cpp`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := filepath.Abs(srcStr)
+		if err != nil {
+			return err
+		}
+
+		w := watcher{path: path}
+		if langStr != "" {
+			if w.lang, err = provider.ParseLang(langStr); err != nil {
+				return err
+			}
+		}
+
+		fsWatcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer fsWatcher.Close()
+
+		// Editors often save by replacing the file, so its directory is watched
+		// rather than the file itself
+		if err := fsWatcher.Add(filepath.Dir(path)); err != nil {
+			return err
+		}
+
+		keys, restore := readKeys()
+		defer restore()
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+
+		if isTextOutput() {
+			fmt.Fprintf(os.Stderr, "watching %s: press t to test, s to submit, q to quit (or add a `tinycode: submit` comment)\n", srcStr)
+		}
+
+		// A marker left over from a previous session should not trigger a
+		// submission right away
+		if _, marked, err := w.read(); err == nil {
+			w.marked = marked
+		}
+		w.update(true)
+
+		debounce := time.NewTimer(debounceDelay)
+		debounce.Stop()
+		samplesChanged := false
+
+		for {
+			select {
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return nil
+				}
+				if filepath.Clean(event.Name) == path {
+					debounce.Reset(debounceDelay)
+				} else if isSampleFile(event.Name) {
+					samplesChanged = true
+					debounce.Reset(debounceDelay)
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return nil
+				}
+				log.Printf("watch error: %s", err)
+			case <-debounce.C:
+				w.update(samplesChanged)
+				samplesChanged = false
+			case key := <-keys:
+				switch key {
+				case 't', 'T':
+					w.update(true)
+				case 's', 'S':
+					w.submit()
+				case 'q', 'Q':
+					return nil
+				}
+			case <-interrupt:
+				return nil
+			}
+		}
+	},
+}
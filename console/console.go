@@ -0,0 +1,18 @@
+package console
+
+import (
+	"golang.org/x/crypto/ssh/terminal"
+	"os"
+)
+
+// IsTerminal is true if f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return terminal.IsTerminal(int(f.Fd()))
+}
+
+// Cbreak switches the terminal f is connected to into cbreak mode, where each
+// keystroke is read as it is typed and not echoed, while output is processed
+// as usual. It returns a function restoring the previous mode.
+func Cbreak(f *os.File) (func() error, error) {
+	return cbreak(int(f.Fd()))
+}
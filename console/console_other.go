@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package console
+
+import "fmt"
+
+func cbreak(fd int) (func() error, error) {
+	return nil, fmt.Errorf("cbreak mode is not supported on this platform")
+}
@@ -18,17 +18,20 @@ type Track struct {
 }
 
 type ChallengeData struct {
-	Solved      bool     `json:"solved"`
-	Attempted   bool     `json:"attempted"`
-	ContestSlug string   `json:"contest_slug"`
-	Slug        string   `json:"slug"`
-	Name        string   `json:"name"`
-	Preview     string   `json:"preview"`
-	Category    string   `json:"category"`
-	BodyHtml    string   `json:"body_html"`
-	Languages   []string `json:"languages"`
-	Track       Track    `json:"track"`
-	MaxScore    int64    `json:"max_score"`
+	Id             int64    `json:"id"`
+	Solved         bool     `json:"solved"`
+	Attempted      bool     `json:"attempted"`
+	ContestSlug    string   `json:"contest_slug"`
+	Slug           string   `json:"slug"`
+	Name           string   `json:"name"`
+	Preview        string   `json:"preview"`
+	Category       string   `json:"category"`
+	BodyHtml       string   `json:"body_html"`
+	Languages      []string `json:"languages"`
+	Track          Track    `json:"track"`
+	MaxScore       int64    `json:"max_score"`
+	DifficultyName string   `json:"difficulty_name"`
+	PlaylistSlug   string   `json:"-"` // the playlist it was checked out from, if any
 
 	CTemplate     string `json:"c_template"`
 	CTemplateHead string `json:"c_template_head"`
@@ -129,6 +132,22 @@ type ChallengeData struct {
 	Java15Template     string `json:"java15_template"`
 	Java15TemplateHead string `json:"java15_template_head"`
 	Java15TemplateTail string `json:"java15_template_tail"`
+
+	MysqlTemplate     string `json:"mysql_template"`
+	MysqlTemplateHead string `json:"mysql_template_head"`
+	MysqlTemplateTail string `json:"mysql_template_tail"`
+
+	OracleTemplate     string `json:"oracle_template"`
+	OracleTemplateHead string `json:"oracle_template_head"`
+	OracleTemplateTail string `json:"oracle_template_tail"`
+
+	TsqlTemplate     string `json:"tsql_template"`
+	TsqlTemplateHead string `json:"tsql_template_head"`
+	TsqlTemplateTail string `json:"tsql_template_tail"`
+
+	Db2Template     string `json:"db2_template"`
+	Db2TemplateHead string `json:"db2_template_head"`
+	Db2TemplateTail string `json:"db2_template_tail"`
 }
 
 func (data *ChallengeData) promptHtmlFilename() string {
@@ -170,7 +189,8 @@ func (data *ChallengeData) Snippet(lang provider.Lang) (string, error) {
 
 	output := fmt.Sprintf("%s%s%s", head, template, tail)
 
-	if output != "" {
+	if output != "" || lang.IsSQL() {
+		// queries are mostly written from scratch
 		return output, nil
 	} else {
 		return output, fmt.Errorf("no snippet for lang %s (hackerrank %s) found in server response", lang, local)
@@ -182,9 +202,48 @@ func (data *ChallengeData) Prompt() string {
 }
 
 func (data *ChallengeData) Files() (map[string]string, error) {
-	return map[string]string{
+	files := map[string]string{
 		data.promptHtmlFilename(): data.BodyHtml,
-	}, nil
+	}
+
+	// the tables are loaded by local runs, there are no sample inputs
+	if data.isDatabaseTrack() {
+		if schema := data.sqliteSchema(); schema != "" {
+			files[data.schemaFilename()] = schema
+		}
+	}
+
+	return files, nil
+}
+
+func (data *ChallengeData) Details() provider.ChallengeDetails {
+	details := provider.ChallengeDetails{
+		Title:      data.Name,
+		Difficulty: data.DifficultyName,
+	}
+
+	// the subdomain of a challenge is the closest thing to a tag
+	if data.Track.Name != "" {
+		details.Tags = []string{data.Track.Name}
+	}
+
+	return details
+}
+
+func (data *ChallengeData) Summarize() provider.ChallengeSummary {
+	summary := provider.ChallengeSummary{
+		Filters: data.Identify(),
+		Details: data.Details(),
+		Status:  provider.Todo,
+	}
+
+	if data.Solved {
+		summary.Status = provider.Solved
+	} else if data.Attempted {
+		summary.Status = provider.Attempted
+	}
+
+	return summary
 }
 
 func (data *ChallengeData) Identify() provider.Filters {
@@ -192,6 +251,7 @@ func (data *ChallengeData) Identify() provider.Filters {
 	output.AddFilter("slug", data.Slug)
 	output.AddFilter("category", data.Category)
 	output.AddFilter("contest", data.ContestSlug)
+	output.AddFilter("playlist", data.PlaylistSlug)
 	return output
 }
 
@@ -210,12 +270,15 @@ type TestcaseData struct {
 type Status string
 
 const (
-	Accepted         Status = "Accepted"
-	Success                 = "Success"
-	Processing              = "Processing"
-	CompilationError        = "Compilation error"
-	RuntimeError            = "Runtime Error"
-	TimeoutError            = "Terminated due to timeout"
+	Accepted          Status = "Accepted"
+	Success                  = "Success"
+	Processing               = "Processing"
+	CompilationError         = "Compilation error"
+	RuntimeError             = "Runtime Error"
+	TimeoutError             = "Terminated due to timeout"
+	WrongAnswer              = "Wrong Answer"
+	SegmentationFault        = "Segmentation Fault"
+	AbortCalled              = "Abort Called"
 )
 
 type SubmissionState struct {
@@ -255,6 +318,25 @@ func (state *SubmissionState) Identify() string {
 	)
 }
 
+func (state *SubmissionState) Class() provider.VerdictClass {
+	if state.HasSucceeded() {
+		return provider.Accepted
+	}
+
+	switch state.Status {
+	case WrongAnswer:
+		return provider.WrongAnswer
+	case CompilationError:
+		return provider.CompileError
+	case RuntimeError, SegmentationFault, AbortCalled:
+		return provider.RuntimeError
+	case TimeoutError:
+		return provider.TimeLimitExceeded
+	default:
+		return provider.OtherError
+	}
+}
+
 func (state *SubmissionState) findFirstFailedTestcase() int {
 	for idx, msg := range state.TestcaseMessage {
 		if msg != Success {
@@ -274,19 +356,12 @@ func (state *SubmissionState) ErrorReport() *provider.ErrorReport {
 	}
 
 	if state.CompileStatus != 0 {
-		msgSplit := strings.Split(state.CompileMessage, ":")
-
-		if len(msgSplit) > 0 {
-			output.ErrorMsg = msgSplit[0]
-		}
-
-		if len(msgSplit) > 1 {
-			output.CtxHeader = msgSplit[1]
-		}
-
-		if len(msgSplit) > 2 {
-			output.CtxMsg = strings.Join(msgSplit[2:], "\n")
-		}
+		// the message is kept whole, so that the positions it refers to (e.g.
+		// Solution.java:5) can be mapped back to the source
+		message := strings.TrimSpace(state.CompileMessage)
+		first, _, _ := strings.Cut(message, "\n")
+		output.ErrorMsg = first
+		output.CtxMsg = fmt.Sprintf("%s\n", message)
 	} else {
 		// Find the first failed testcase
 		firstFailedIdx := state.findFirstFailedTestcase()
@@ -344,3 +419,53 @@ func (state *SubmissionState) Statistics() provider.SubmissionStatistics {
 
 	return stats
 }
+
+type Hacker struct {
+	Id       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type Badge struct {
+	BadgeName       string `json:"badge_name"`
+	BadgeType       string `json:"badge_type"`
+	Stars           int64  `json:"stars"`
+	Solved          int64  `json:"solved"`
+	TotalChallenges int64  `json:"total_challenges"`
+}
+
+type PracticeScore struct {
+	Score float64 `json:"score"`
+	Rank  int64   `json:"rank"`
+}
+
+type TrackScore struct {
+	Name     string        `json:"name"`
+	Slug     string        `json:"slug"`
+	Practice PracticeScore `json:"practice"`
+}
+
+func NewProfile(hacker *Hacker, badges []Badge, scores []TrackScore) *provider.Profile {
+	profile := provider.Profile{Username: hacker.Username}
+
+	for _, badge := range badges {
+		profile.Badges = append(profile.Badges, provider.Badge{
+			Name:   badge.BadgeName,
+			Stars:  badge.Stars,
+			Solved: badge.Solved,
+			Total:  badge.TotalChallenges,
+		})
+	}
+
+	for _, score := range scores {
+		if score.Practice.Score == 0 {
+			continue
+		}
+		profile.Scores = append(profile.Scores, provider.Score{
+			Track: score.Name,
+			Score: score.Practice.Score,
+			Rank:  score.Practice.Rank,
+		})
+	}
+
+	return &profile
+}
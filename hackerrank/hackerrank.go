@@ -7,6 +7,7 @@ import (
 	"github.com/brokad/tinycode/provider"
 	"golang.org/x/crypto/ssh/terminal"
 	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
@@ -27,13 +28,35 @@ func encodeFilters(filters map[string][]string) string {
 }
 
 type Client struct {
-	transport  provider.TransportClient
-	DoPurchase bool // optional
+	transport    provider.TransportClient
+	DoPurchase   bool     // optional
+	Pick         string   // how FindNextChallenge picks challenges, First if empty
+	Skip         []string // slugs of challenges FindNextChallenge passes over
+	TaxonomyPath string   // where the taxonomy is cached, not cached if empty
+	taxonomy     *Taxonomy
+	onProgress   func(provider.Progress)
 }
 
+// Strategies to pick the next challenge with
+const (
+	First  = "first" // in the order of HackerRank
+	Random = "random"
+)
+
+// pageSize is the number of challenges asked for at once
+const pageSize = 50
+
+// randomAttempts is how many random challenges are drawn before giving up, if
+// they are all skipped
+const randomAttempts = 10
+
 func NewClient(base *url.URL) *Client {
 	transport := provider.NewTransportClient(*base)
-	return &Client{transport, false}
+	return &Client{transport: transport}
+}
+
+func (client *Client) OnProgress(onProgress func(provider.Progress)) {
+	client.onProgress = onProgress
 }
 
 func (client *Client) Configure(config provider.BackendConfig) error {
@@ -225,6 +248,13 @@ func (client *Client) Do(method string, path string, req interface{}, output int
 }
 
 func (client *Client) DoMany(method string, path string, req interface{}, output interface{}) error {
+	_, err := client.DoPage(method, path, req, output)
+	return err
+}
+
+// DoPage is DoMany for paginated resources, also returning the total number of
+// models beyond this page
+func (client *Client) DoPage(method string, path string, req interface{}, output interface{}) (uint64, error) {
 	type SubmitResponseMany struct {
 		Models json.RawMessage `json:"models"`
 		Total  uint64          `json:"total"`
@@ -233,17 +263,19 @@ func (client *Client) DoMany(method string, path string, req interface{}, output
 	resp := SubmitResponseMany{}
 
 	if err := client.transport.Do(method, path, req, &resp); err != nil {
-		return err
+		return 0, err
 	}
 
 	if err := json.Unmarshal(resp.Models, output); err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return resp.Total, nil
 }
 
-func (client *Client) DoSubmit(contest string, slug string, lang string, code string) (*SubmissionState, error) {
+// DoSubmit submits code, tracking the progress through playlist if it is not
+// empty
+func (client *Client) DoSubmit(contest string, playlist string, slug string, lang string, code string) (*SubmissionState, error) {
 	parsedPath, err := url.Parse(fmt.Sprintf("/rest/contests/%s/challenges/%s/submissions", contest, slug))
 	if err != nil {
 		return nil, err
@@ -251,9 +283,10 @@ func (client *Client) DoSubmit(contest string, slug string, lang string, code st
 	log.Printf("submit path: %s", parsedPath.String())
 
 	req := SubmitRequest{
-		Code:        code,
-		ContestSlug: contest,
-		Language:    lang,
+		Code:         code,
+		ContestSlug:  contest,
+		Language:     lang,
+		PlaylistSlug: playlist,
 	}
 
 	state := SubmissionState{}
@@ -281,6 +314,14 @@ func (client *Client) DoSubmit(contest string, slug string, lang string, code st
 			return &state, nil
 		}
 
+		if client.onProgress != nil {
+			client.onProgress(provider.Progress{
+				Submission: submissionUrl,
+				State:      string(state.Status),
+				Elapsed:    time.Since(start).Milliseconds(),
+			})
+		}
+
 		// Wait a bit before trying again
 		backoff *= 2
 		if time.Now().Add(backoff).Before(start.Add(timeOut)) {
@@ -384,7 +425,45 @@ func (client *Client) IsSignedIn() (bool, error) {
 }
 
 func LocalizeLanguage(lang provider.Lang) (string, error) {
-	return lang.String(), nil
+	switch lang.String() {
+	case provider.MsSQL:
+		return "tsql", nil
+	case provider.OracleSQL:
+		return "oracle", nil
+	case provider.PostgreSQL, provider.PythonData:
+		return "", fmt.Errorf("%s is not available on hackerrank", lang.Pretty())
+	default:
+		return lang.String(), nil
+	}
+}
+
+func (client *Client) GetHacker() (*Hacker, error) {
+	hacker := Hacker{}
+	if err := client.Do("GET", "/rest/contests/master/hackers/me", nil, &hacker); err != nil {
+		return nil, err
+	}
+	return &hacker, nil
+}
+
+func (client *Client) Profile() (*provider.Profile, error) {
+	hacker, err := client.GetHacker()
+	if err != nil {
+		return nil, err
+	}
+
+	var badges []Badge
+	badgesPath := fmt.Sprintf("/rest/hackers/%s/badges", url.PathEscape(hacker.Username))
+	if err := client.DoMany("GET", badgesPath, nil, &badges); err != nil {
+		return nil, err
+	}
+
+	var scores []TrackScore
+	scoresPath := fmt.Sprintf("/rest/hackers/%s/scores_elo", url.PathEscape(hacker.Username))
+	if err := client.transport.Do("GET", scoresPath, nil, &scores); err != nil {
+		return nil, err
+	}
+
+	return NewProfile(hacker, badges, scores), nil
 }
 
 func (client *Client) GetChallenge(filters provider.Filters) (provider.Challenge, error) {
@@ -398,35 +477,77 @@ func (client *Client) GetChallenge(filters provider.Filters) (provider.Challenge
 		return nil, err
 	}
 
-	return client.GetChallengeData(contest, slug)
+	challenge, err := client.GetChallengeData(contest, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.PlaylistSlug = filters.GetFilterOrDefault("playlist")
+	return challenge, nil
 }
 
-func (client *Client) ListChallenges(contest string, track string, offset uint64, limit uint64, filters map[string][]string) ([]ChallengeData, error) {
+// ListPage lists the challenges from offset up to limit of them, along with
+// the total number of challenges matching filters
+func (client *Client) ListPage(contest string, track string, offset uint64, limit uint64, filters map[string][]string) ([]ChallengeData, uint64, error) {
 	path := fmt.Sprintf("/rest/contests/%s", contest)
 	if track != "" {
 		path = fmt.Sprintf("%s/tracks/%s", path, track)
 	}
 
-	type Values struct {
-		Filters map[string][]string `url:"filters"`
-	}
+	params := url.Values{}
+	params.Set("offset", fmt.Sprintf("%d", offset))
+	params.Set("limit", fmt.Sprintf("%d", limit))
 
-	path = fmt.Sprintf("%s/challenges?%s", path, encodeFilters(filters))
+	path = fmt.Sprintf("%s/challenges?%s", path, params.Encode())
+	if encoded := encodeFilters(filters); encoded != "" {
+		path = fmt.Sprintf("%s&%s", path, encoded)
+	}
 	log.Printf("list path: %s", path)
 
 	var output []ChallengeData
-	if err := client.DoMany("GET", path, nil, &output); err != nil {
-		return nil, err
-	} else {
-		return output, nil
+	total, err := client.DoPage("GET", path, nil, &output)
+	if err != nil {
+		return nil, 0, err
 	}
+	return output, total, nil
 }
 
-func (client *Client) FindNextChallenge(filters provider.Filters) (provider.Filters, error) {
+// ListChallenges lists the challenges from offset up to limit of them, a page
+// at a time
+func (client *Client) ListChallenges(contest string, track string, offset uint64, limit uint64, filters map[string][]string) ([]ChallengeData, error) {
+	var output []ChallengeData
+	for uint64(len(output)) < limit {
+		size := limit - uint64(len(output))
+		if size > pageSize {
+			size = pageSize
+		}
+
+		page, total, err := client.ListPage(contest, track, offset, size, filters)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, page...)
+
+		offset += uint64(len(page))
+		if len(page) == 0 || offset >= total {
+			break
+		}
+	}
+	return output, nil
+}
+
+// listParams extracts the contest, track and search parameters of a listing
+// from filters, checking them against the taxonomy
+func (client *Client) listParams(filters provider.Filters) (string, string, map[string][]string, error) {
 	var params = map[string][]string{}
 
 	if difficulty, err := filters.GetFilter("difficulty"); err == nil {
-		params["difficulty"] = []string{difficulty}
+		switch difficulty {
+		case "easy", "medium", "hard", "advanced", "expert":
+			params["difficulty"] = []string{difficulty}
+		default:
+			return "", "", nil, fmt.Errorf("unknown difficulty: %s, must be one of: easy, medium, hard, advanced, expert", difficulty)
+		}
 	}
 
 	if subdomains, err := filters.GetFilter("tags"); err == nil {
@@ -439,56 +560,179 @@ func (client *Client) FindNextChallenge(filters provider.Filters) (provider.Filt
 	if skills, err := filters.GetFilter("skills"); err == nil {
 		params["skills"] = []string{}
 		for _, skill := range strings.Split(skills, ",") {
-			params["skill"] = append(params["skill"], skill)
+			params["skills"] = append(params["skills"], skill)
 		}
 	}
 
-	var output provider.Filters
+	if status, err := filters.GetFilter("status"); err == nil {
+		// HackerRank does not tell attempted challenges apart
+		switch status {
+		case "todo", "attempted", "unsolved":
+			params["status"] = []string{"unsolved"}
+		case "solved":
+			params["status"] = []string{"solved"}
+		default:
+			return "", "", nil, fmt.Errorf("unknown status: %s, must be one of: todo, attempted, solved", status)
+		}
+	}
 
 	contest, err := filters.GetFilter("contest")
 	if err != nil {
-		return output, err
+		return "", "", nil, err
 	}
 
-	var track string
-	if trackFilter, err := filters.GetFilter("track"); err == nil {
-		track = trackFilter
-	} else {
+	taxonomy, err := client.Taxonomy()
+	if err != nil {
+		log.Printf("could not fetch the taxonomy, not checking the search: %s", err)
+	}
+
+	track, err := filters.GetFilter("track")
+	if err != nil {
 		// Not specifying a track explicitly leads to what seems to be a very
 		// tough search for HackerRank's backend. So this is disabled in order
 		// for us to be good citizens.
-		return output, fmt.Errorf(`a --track is required: one of 
-  algorithms
-  data-structures
-  mathematics
-  ai
-  c
-  cpp
-  java
-  python
-  ruby
-  sql
-  databases
-  shell
-  fp
-  regex`)
+		if taxonomy == nil {
+			return "", "", nil, fmt.Errorf("a --track is required: see tinycode tracks")
+		}
+		return "", "", nil, fmt.Errorf("a --track is required: one of %s", strings.Join(taxonomy.TrackSlugs(), ", "))
 	}
 
-	if status, err := filters.GetFilter("status"); err == nil {
-		params["status"] = []string{status}
-	} else {
+	if taxonomy != nil {
+		if err := taxonomy.Validate(track, filters.GetFilterOrDefault("tags"), filters.GetFilterOrDefault("skills")); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	return contest, track, params, nil
+}
+
+func (client *Client) FindNextChallenge(filters provider.Filters) (provider.Filters, error) {
+	var output provider.Filters
+
+	if _, err := filters.GetFilter("playlist"); err == nil {
+		challenge, err := client.nextPlaylistChallenge(filters)
+		if err != nil {
+			return output, err
+		} else if challenge == nil {
+			return output, fmt.Errorf("could not find challenge: every challenge of the playlist is solved")
+		}
+		return challenge.Identify(), nil
+	}
+
+	contest, track, params, err := client.listParams(filters)
+	if err != nil {
+		return output, err
+	}
+
+	if _, ok := params["status"]; !ok {
 		params["status"] = []string{"unsolved"}
 	}
 
-	if challenges, err := client.ListChallenges(contest, track, 0, 1, params); err != nil {
+	var challenge *ChallengeData
+	switch client.Pick {
+	case "", First:
+		challenge, err = client.firstChallenge(contest, track, params)
+	case Random:
+		challenge, err = client.randomChallenge(contest, track, params)
+	default:
+		err = fmt.Errorf("unknown strategy: %s, must be one of: %s, %s", client.Pick, First, Random)
+	}
+
+	if err != nil {
 		return output, err
-	} else {
-		if len(challenges) > 0 {
-			return challenges[0].Identify(), nil
+	} else if challenge == nil {
+		return output, fmt.Errorf("could not find challenge")
+	}
+	return challenge.Identify(), nil
+}
+
+func (client *Client) isSkipped(challenge *ChallengeData) bool {
+	for _, slug := range client.Skip {
+		if slug == challenge.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+// firstChallenge is the first challenge matching params which is not skipped,
+// nil if there is none
+func (client *Client) firstChallenge(contest string, track string, params map[string][]string) (*ChallengeData, error) {
+	for offset := uint64(0); ; {
+		page, total, err := client.ListPage(contest, track, offset, pageSize, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page {
+			if !client.isSkipped(&page[i]) {
+				return &page[i], nil
+			}
+		}
+
+		offset += uint64(len(page))
+		if len(page) == 0 || offset >= total {
+			return nil, nil
+		}
+	}
+}
+
+// randomChallenge draws a challenge matching params which is not skipped, nil
+// if there is none
+func (client *Client) randomChallenge(contest string, track string, params map[string][]string) (*ChallengeData, error) {
+	_, total, err := client.ListPage(contest, track, 0, 1, params)
+	if err != nil || total == 0 {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for attempt := 0; attempt < randomAttempts; attempt++ {
+		page, _, err := client.ListPage(contest, track, uint64(random.Int63n(int64(total))), 1, params)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(page) != 0 && !client.isSkipped(&page[0]) {
+			return &page[0], nil
+		}
+	}
+
+	// the skipped challenges may well be most of them
+	return client.firstChallenge(contest, track, params)
+}
+
+func (client *Client) List(filters provider.Filters, offset uint64, limit uint64) ([]provider.ChallengeSummary, error) {
+	var challenges []ChallengeData
+	if _, err := filters.GetFilter("playlist"); err == nil {
+		if challenges, err = client.playlistChallenges(filters); err != nil {
+			return nil, err
+		}
+
+		if offset < uint64(len(challenges)) {
+			challenges = challenges[offset:]
 		} else {
-			return output, fmt.Errorf("could not find challenge")
+			challenges = nil
+		}
+	} else {
+		contest, track, params, err := client.listParams(filters)
+		if err != nil {
+			return nil, err
 		}
+
+		if challenges, err = client.ListChallenges(contest, track, offset, limit, params); err != nil {
+			return nil, err
+		}
+	}
+
+	var output []provider.ChallengeSummary
+	for _, challenge := range challenges {
+		if uint64(len(output)) == limit {
+			break
+		}
+		output = append(output, challenge.Summarize())
 	}
+
+	return output, nil
 }
 
 func (client *Client) Submit(filters provider.Filters, lang provider.Lang, code string) (provider.SubmissionReport, error) {
@@ -512,7 +756,7 @@ func (client *Client) Submit(filters provider.Filters, lang provider.Lang, code
 		return nil, err
 	}
 
-	state, err := client.DoSubmit(contest, slug, local, code)
+	state, err := client.DoSubmit(contest, filters.GetFilterOrDefault("playlist"), slug, local, code)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,185 @@
+package hackerrank
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// Playlists of HackerRank, whose challenges are solved in order and whose
+// progress is tracked on their page
+const (
+	InterviewPreparationKit = "interview-preparation-kit"
+	ThirtyDaysOfCode        = "30-days-of-code"
+	TenDaysOfJavascript     = "10-days-of-javascript"
+)
+
+// Playlist is a list of challenges kept by HackerRank, such as the Interview
+// Preparation Kit. Kits are split into sections, which are playlists of their
+// own.
+type Playlist struct {
+	Name     string     `json:"name"`
+	Slug     string     `json:"slug"`
+	Sections []Playlist `json:"playlists"`
+}
+
+// Section is a section of a playlist along with its challenges
+type Section struct {
+	Name       string
+	Slug       string
+	Challenges []ChallengeData
+}
+
+func (section *Section) Solved() int {
+	solved := 0
+	for _, challenge := range section.Challenges {
+		if challenge.Solved {
+			solved++
+		}
+	}
+	return solved
+}
+
+func (client *Client) GetPlaylist(slug string) (*Playlist, error) {
+	playlist := Playlist{}
+	if err := client.Do("GET", fmt.Sprintf("/rest/playlists/%s", url.PathEscape(slug)), nil, &playlist); err != nil {
+		return nil, fmt.Errorf("could not fetch playlist %s (e.g. %s, %s or %s): %s", slug, InterviewPreparationKit, ThirtyDaysOfCode, TenDaysOfJavascript, err)
+	}
+	return &playlist, nil
+}
+
+// PlaylistChallenges are all the challenges of a playlist (or a section of
+// one), in order
+func (client *Client) PlaylistChallenges(slug string) ([]ChallengeData, error) {
+	var output []ChallengeData
+	for offset := uint64(0); ; {
+		params := url.Values{}
+		params.Set("offset", fmt.Sprintf("%d", offset))
+		params.Set("limit", fmt.Sprintf("%d", pageSize))
+
+		path := fmt.Sprintf("/rest/playlists/%s/challenges?%s", url.PathEscape(slug), params.Encode())
+		log.Printf("playlist path: %s", path)
+
+		var page []ChallengeData
+		total, err := client.DoPage("GET", path, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, page...)
+
+		offset += uint64(len(page))
+		if len(page) == 0 || offset >= total {
+			return output, nil
+		}
+	}
+}
+
+// Sections are those of the given playlist along with their challenges, or
+// only the given section if there is one. Playlists with no sections are
+// their own single section.
+func (client *Client) Sections(playlist string, section string) ([]Section, error) {
+	parent, err := client.GetPlaylist(playlist)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := parent.Sections
+	if len(sections) == 0 {
+		sections = []Playlist{*parent}
+	}
+
+	if section != "" {
+		var slugs []string
+		for _, s := range sections {
+			slugs = append(slugs, s.Slug)
+		}
+
+		if i := indexOf(slugs, section); i != -1 {
+			sections = sections[i : i+1]
+		} else {
+			return nil, fmt.Errorf("unknown section of %s: %s, must be one of: %s", playlist, section, strings.Join(slugs, ", "))
+		}
+	}
+
+	var output []Section
+	for _, s := range sections {
+		challenges, err := client.PlaylistChallenges(s.Slug)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range challenges {
+			challenges[i].PlaylistSlug = playlist
+			if challenges[i].ContestSlug == "" {
+				challenges[i].ContestSlug = "master"
+			}
+		}
+		output = append(output, Section{Name: s.Name, Slug: s.Slug, Challenges: challenges})
+	}
+	return output, nil
+}
+
+// matchesPlaylistFilters applies the search filters which HackerRank does not
+// apply to playlists itself
+func matchesPlaylistFilters(challenge *ChallengeData, filters provider.Filters) (bool, error) {
+	if difficulty, err := filters.GetFilter("difficulty"); err == nil && !strings.EqualFold(difficulty, challenge.DifficultyName) {
+		return false, nil
+	}
+
+	if status, err := filters.GetFilter("status"); err == nil {
+		switch status {
+		case "todo", "attempted", "unsolved":
+			return !challenge.Solved, nil
+		case "solved":
+			return challenge.Solved, nil
+		default:
+			return false, fmt.Errorf("unknown status: %s, must be one of: todo, attempted, solved", status)
+		}
+	}
+
+	return true, nil
+}
+
+// playlistChallenges are the challenges of the playlist (and section) of the
+// filters which match them, in order
+func (client *Client) playlistChallenges(filters provider.Filters) ([]ChallengeData, error) {
+	playlist, err := filters.GetFilter("playlist")
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := client.Sections(playlist, filters.GetFilterOrDefault("section"))
+	if err != nil {
+		return nil, err
+	}
+
+	var output []ChallengeData
+	for _, section := range sections {
+		for i := range section.Challenges {
+			if ok, err := matchesPlaylistFilters(&section.Challenges[i], filters); err != nil {
+				return nil, err
+			} else if ok {
+				output = append(output, section.Challenges[i])
+			}
+		}
+	}
+	return output, nil
+}
+
+// nextPlaylistChallenge is the first challenge of the playlist of the filters
+// which is neither solved nor skipped
+func (client *Client) nextPlaylistChallenge(filters provider.Filters) (*ChallengeData, error) {
+	challenges, err := client.playlistChallenges(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range challenges {
+		if !challenges[i].Solved && !client.isSkipped(&challenges[i]) {
+			return &challenges[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,148 @@
+package hackerrank
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	htmlTable = regexp.MustCompile(`(?is)<table[^>]*>(.*?)</table>`)
+	htmlRow   = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	htmlCell  = regexp.MustCompile(`(?is)<t[hd][^>]*>(.*?)</t[hd]>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+
+	// tableName matches the way statements introduce tables, e.g. "The CITY
+	// table is described as follows"
+	tableName = regexp.MustCompile(`(?i)\b(?:the|a)\s+(\w+)\s+table\b`)
+)
+
+// isDatabaseTrack is true for the tracks whose challenges are queries
+func (data *ChallengeData) isDatabaseTrack() bool {
+	return data.Track.TrackSlug == "sql" || data.Track.TrackSlug == "databases"
+}
+
+// schemaFilename is where the tables are written, which the SQL runner loads
+// before the input of every run
+func (data *ChallengeData) schemaFilename() string {
+	return "schema.sql"
+}
+
+// sqlTable is a table of a database challenge, as described in its statement
+type sqlTable struct {
+	name    string
+	columns []string // with their types, if known
+	inserts []string
+}
+
+func htmlCells(row string) []string {
+	var cells []string
+	for _, match := range htmlCell.FindAllStringSubmatch(row, -1) {
+		cells = append(cells, strings.TrimSpace(provider.HtmlToText(match[1])))
+	}
+	return cells
+}
+
+// isColumnHeader is true for the header of a table describing columns, e.g.
+// "Field | Type"
+func isColumnHeader(cells []string) bool {
+	return len(cells) >= 2 &&
+		(strings.EqualFold(cells[0], "field") || strings.EqualFold(cells[0], "column")) &&
+		strings.EqualFold(cells[1], "type")
+}
+
+// sqlValue quotes value unless it is a number, or NULL
+func sqlValue(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil || strings.EqualFold(value, "null") {
+		return value
+	}
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+// sqlTables extracts the tables of the statement, that is the descriptions of
+// their columns and their sample rows. A table is named after the last one
+// mentioned before it, and tables with no name are left out. Schemas shown as
+// images cannot be extracted.
+func (data *ChallengeData) sqlTables() []*sqlTable {
+	var tables []*sqlTable
+	byName := map[string]*sqlTable{}
+
+	last := 0
+	for _, match := range htmlTable.FindAllStringSubmatchIndex(data.BodyHtml, -1) {
+		// tags are kept apart, e.g. "<p>Input</p><p>The CITY table"
+		before := provider.HtmlToText(htmlTag.ReplaceAllString(data.BodyHtml[last:match[0]], " "))
+		last = match[1]
+
+		names := tableName.FindAllStringSubmatch(before, -1)
+		if len(names) == 0 {
+			continue
+		}
+		name := names[len(names)-1][1]
+
+		var rows [][]string
+		for _, row := range htmlRow.FindAllStringSubmatch(data.BodyHtml[match[2]:match[3]], -1) {
+			if cells := htmlCells(row[1]); len(cells) != 0 {
+				rows = append(rows, cells)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		table, ok := byName[strings.ToUpper(name)]
+		if !ok {
+			table = &sqlTable{name: name}
+			byName[strings.ToUpper(name)] = table
+			tables = append(tables, table)
+		}
+
+		if isColumnHeader(rows[0]) {
+			table.columns = nil
+			for _, row := range rows[1:] {
+				column := row[0]
+				if len(row) > 1 && row[1] != "" {
+					column = fmt.Sprintf("%s %s", row[0], row[1])
+				}
+				table.columns = append(table.columns, column)
+			}
+		} else {
+			// sample rows, under the names of their columns
+			if table.columns == nil {
+				table.columns = rows[0]
+			}
+			for _, row := range rows[1:] {
+				if len(row) != len(rows[0]) {
+					continue
+				}
+
+				var values []string
+				for _, value := range row {
+					values = append(values, sqlValue(value))
+				}
+				insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", name, strings.Join(rows[0], ", "), strings.Join(values, ", "))
+				table.inserts = append(table.inserts, insert)
+			}
+		}
+	}
+
+	return tables
+}
+
+// sqliteSchema is a script creating the tables of a database challenge and
+// filling them with their sample rows, for sqlite3 to run. It is empty if no
+// table could be extracted.
+func (data *ChallengeData) sqliteSchema() string {
+	var buf strings.Builder
+	for _, table := range data.sqlTables() {
+		if len(table.columns) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "CREATE TABLE %s (%s);\n", table.name, strings.Join(table.columns, ", "))
+		for _, insert := range table.inserts {
+			fmt.Fprintf(&buf, "%s\n", insert)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,263 @@
+package hackerrank
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaxonomyFilename is the name of the file caching the taxonomy, in the
+// configuration directory
+const TaxonomyFilename = "hackerrank-tracks.json"
+
+// taxonomyTTL is how long the cached taxonomy is trusted before being fetched
+// again, HackerRank seldom adds tracks
+const taxonomyTTL = 7 * 24 * time.Hour
+
+// Topic is a subdomain or a skill of a track
+type Topic struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type TrackNode struct {
+	Name       string  `json:"name"`
+	Slug       string  `json:"slug"`
+	Subdomains []Topic `json:"subdomains"`
+	Skills     []Topic `json:"skills,omitempty"`
+}
+
+// Taxonomy is the tree of the tracks of HackerRank, and of their subdomains
+// (searched with --tags) and skills (searched with --skills)
+type Taxonomy struct {
+	Fetched time.Time   `json:"fetched"`
+	Tracks  []TrackNode `json:"tracks"`
+}
+
+// TaxonomyPath is where the taxonomy is cached, in the configuration directory
+func TaxonomyPath(configDir string) string {
+	return filepath.Join(configDir, TaxonomyFilename)
+}
+
+// LoadTaxonomy reads the taxonomy cached at path, nil if there is none
+func LoadTaxonomy(path string) (*Taxonomy, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	taxonomy := Taxonomy{}
+	if err := json.Unmarshal(data, &taxonomy); err != nil {
+		return nil, fmt.Errorf("invalid taxonomy cache %s: %s", path, err)
+	}
+	return &taxonomy, nil
+}
+
+func (taxonomy *Taxonomy) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(taxonomy, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write then rename, so that the cache is never left half-written
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (taxonomy *Taxonomy) IsStale(now time.Time) bool {
+	return now.Sub(taxonomy.Fetched) > taxonomyTTL
+}
+
+func (taxonomy *Taxonomy) Track(slug string) *TrackNode {
+	for i := range taxonomy.Tracks {
+		if taxonomy.Tracks[i].Slug == slug {
+			return &taxonomy.Tracks[i]
+		}
+	}
+	return nil
+}
+
+func (taxonomy *Taxonomy) TrackSlugs() []string {
+	var slugs []string
+	for _, track := range taxonomy.Tracks {
+		slugs = append(slugs, track.Slug)
+	}
+	return slugs
+}
+
+func topicSlugs(topics []Topic) []string {
+	var slugs []string
+	for _, topic := range topics {
+		slugs = append(slugs, topic.Slug)
+	}
+	return slugs
+}
+
+func (track *TrackNode) SubdomainSlugs() []string {
+	return topicSlugs(track.Subdomains)
+}
+
+func (track *TrackNode) SkillSlugs() []string {
+	return topicSlugs(track.Skills)
+}
+
+// Subdomains are the slugs of the subdomains of the given track, or of all
+// tracks if it is empty
+func (taxonomy *Taxonomy) Subdomains(track string) []string {
+	return taxonomy.topics(track, (*TrackNode).SubdomainSlugs)
+}
+
+// Skills are the slugs of the skills of the given track, or of all tracks if
+// it is empty
+func (taxonomy *Taxonomy) Skills(track string) []string {
+	return taxonomy.topics(track, (*TrackNode).SkillSlugs)
+}
+
+func (taxonomy *Taxonomy) topics(track string, slugs func(*TrackNode) []string) []string {
+	if track != "" {
+		if node := taxonomy.Track(track); node != nil {
+			return slugs(node)
+		}
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var output []string
+	for i := range taxonomy.Tracks {
+		for _, slug := range slugs(&taxonomy.Tracks[i]) {
+			if !seen[slug] {
+				seen[slug] = true
+				output = append(output, slug)
+			}
+		}
+	}
+	sort.Strings(output)
+	return output
+}
+
+func indexOf(slugs []string, slug string) int {
+	for i, s := range slugs {
+		if s == slug {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(slugs []string, slug string) bool {
+	return indexOf(slugs, slug) != -1
+}
+
+// Validate checks that the track exists, and that the given (comma-separated)
+// subdomains and skills belong to it
+func (taxonomy *Taxonomy) Validate(track string, subdomains string, skills string) error {
+	node := taxonomy.Track(track)
+	if node == nil {
+		return fmt.Errorf("unknown track: %s, must be one of: %s", track, strings.Join(taxonomy.TrackSlugs(), ", "))
+	}
+
+	if subdomains != "" {
+		for _, subdomain := range strings.Split(subdomains, ",") {
+			if !contains(node.SubdomainSlugs(), subdomain) {
+				return fmt.Errorf("unknown subdomain of %s: %s, must be one of: %s", track, subdomain, strings.Join(node.SubdomainSlugs(), ", "))
+			}
+		}
+	}
+
+	// not all tracks list their skills
+	if skills != "" && len(node.Skills) != 0 {
+		for _, skill := range strings.Split(skills, ",") {
+			if !contains(node.SkillSlugs(), skill) {
+				return fmt.Errorf("unknown skill of %s: %s, must be one of: %s", track, skill, strings.Join(node.SkillSlugs(), ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// FetchTaxonomy asks HackerRank for its tracks, along with their subdomains and
+// skills
+func (client *Client) FetchTaxonomy() (*Taxonomy, error) {
+	type TrackModel struct {
+		Name     string  `json:"name"`
+		Slug     string  `json:"slug"`
+		Children []Topic `json:"children"`
+	}
+
+	var tracks []TrackModel
+	if err := client.DoMany("GET", "/rest/contests/master/tracks", nil, &tracks); err != nil {
+		return nil, err
+	}
+
+	taxonomy := Taxonomy{Fetched: time.Now()}
+	for _, track := range tracks {
+		node := TrackNode{Name: track.Name, Slug: track.Slug, Subdomains: track.Children}
+
+		skillsPath := fmt.Sprintf("/rest/contests/master/tracks/%s/skills", track.Slug)
+		if err := client.DoMany("GET", skillsPath, nil, &node.Skills); err != nil {
+			log.Printf("could not fetch the skills of %s: %s", track.Slug, err)
+		}
+
+		taxonomy.Tracks = append(taxonomy.Tracks, node)
+	}
+
+	if len(taxonomy.Tracks) == 0 {
+		return nil, fmt.Errorf("hackerrank did not list any track")
+	}
+	return &taxonomy, nil
+}
+
+// Taxonomy is the taxonomy cached at TaxonomyPath, fetched again if it is
+// missing or stale. A stale cache is still used if HackerRank cannot be
+// reached.
+func (client *Client) Taxonomy() (*Taxonomy, error) {
+	if client.taxonomy != nil {
+		return client.taxonomy, nil
+	}
+
+	var cached *Taxonomy
+	if client.TaxonomyPath != "" {
+		var err error
+		if cached, err = LoadTaxonomy(client.TaxonomyPath); err != nil {
+			log.Printf("ignoring the taxonomy cache: %s", err)
+		} else if cached != nil && !cached.IsStale(time.Now()) {
+			client.taxonomy = cached
+			return cached, nil
+		}
+	}
+
+	fetched, err := client.FetchTaxonomy()
+	if err != nil {
+		if cached != nil {
+			log.Printf("could not fetch the taxonomy, using a stale one: %s", err)
+			client.taxonomy = cached
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if client.TaxonomyPath != "" {
+		if err := fetched.Save(client.TaxonomyPath); err != nil {
+			log.Printf("could not cache the taxonomy: %s", err)
+		}
+	}
+
+	client.taxonomy = fetched
+	return fetched, nil
+}
@@ -0,0 +1,106 @@
+// Package history keeps a local log of checkouts and submissions, one JSON
+// entry per line
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const Filename = "history.jsonl"
+
+type Event string
+
+const (
+	Checkout Event = "checkout"
+	Submit         = "submit"
+)
+
+type Entry struct {
+	Time       time.Time             `json:"time"`
+	Event      Event                 `json:"event"`
+	Provider   string                `json:"provider"`
+	Identity   map[string]string     `json:"identity"`
+	Title      string                `json:"title,omitempty"`
+	Difficulty string                `json:"difficulty,omitempty"`
+	Tags       []string              `json:"tags,omitempty"`
+	TagSlugs   map[string]string     `json:"tag_slugs,omitempty"` // by name
+	Lang       string                `json:"lang,omitempty"`
+	Class      provider.VerdictClass `json:"class,omitempty"` // of submissions only
+}
+
+func NewEntry(event Event, backend string, identity provider.Filters, details provider.ChallengeDetails, lang provider.Lang) Entry {
+	return Entry{
+		Time:       time.Now(),
+		Event:      event,
+		Provider:   backend,
+		Identity:   identity.Map(),
+		Title:      details.Title,
+		Difficulty: details.Difficulty,
+		Tags:       details.Tags,
+		TagSlugs:   details.TagSlugs,
+		Lang:       lang.String(),
+	}
+}
+
+// Problem identifies the problem of the entry across providers
+func (entry *Entry) Problem() string {
+	return fmt.Sprintf("%s/%s/%s", entry.Provider, entry.Identity["contest"], entry.Identity["slug"])
+}
+
+func (entry *Entry) IsAccepted() bool {
+	return entry.Event == Submit && entry.Class == provider.Accepted
+}
+
+// Path is where the history is kept, in the configuration directory
+func Path(configDir string) string {
+	return filepath.Join(configDir, Filename)
+}
+
+func Append(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(entry)
+}
+
+// Read returns all the entries of the history, oldest first. A missing history
+// is an empty one, and lines which cannot be parsed are skipped.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("skipping line %d of %s: %s", line, path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
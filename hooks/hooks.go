@@ -0,0 +1,202 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	PreSubmit  = "pre-submit"
+	PostSubmit = "post-submit"
+)
+
+// Hook is a shell command run around submissions, e.g.
+//
+//	[[hooks.pre-submit]]
+//	name = "gofmt"
+//	run = "test -z \"$(gofmt -l $TINYCODE_REGION_FILE)\""
+//	langs = ["golang"]
+type Hook struct {
+	Name  string   `mapstructure:"name"`
+	Run   string   `mapstructure:"run"`
+	Langs []string `mapstructure:"langs"` // all languages if empty
+}
+
+type Config struct {
+	PreSubmit  []Hook `mapstructure:"pre-submit"`
+	PostSubmit []Hook `mapstructure:"post-submit"`
+}
+
+// Merge appends the hooks of other after those of config
+func (config Config) Merge(other Config) Config {
+	return Config{
+		PreSubmit:  append(append([]Hook{}, config.PreSubmit...), other.PreSubmit...),
+		PostSubmit: append(append([]Hook{}, config.PostSubmit...), other.PostSubmit...),
+	}
+}
+
+func (hook *Hook) String() string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return hook.Run
+}
+
+func (hook *Hook) AppliesTo(lang provider.Lang) bool {
+	if len(hook.Langs) == 0 {
+		return true
+	}
+	for _, l := range hook.Langs {
+		if lang.Is(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// Context describes the submission hooks are run for. It is passed to them in
+// TINYCODE_* environment variables.
+type Context struct {
+	Path      string // empty if the solution was read from stdin
+	Workspace string // empty if not in a workspace
+	Provider  string
+	Lang      provider.Lang
+	Filters   provider.Filters
+	Code      string // the decoded submit region
+}
+
+func (ctx *Context) env(stage string, regionFile string) []string {
+	env := append(os.Environ(),
+		"TINYCODE_HOOK="+stage,
+		"TINYCODE_PATH="+ctx.Path,
+		"TINYCODE_WORKSPACE="+ctx.Workspace,
+		"TINYCODE_PROVIDER="+ctx.Provider,
+		"TINYCODE_LANG="+ctx.Lang.String(),
+		"TINYCODE_REGION="+ctx.Code,
+		"TINYCODE_REGION_FILE="+regionFile,
+	)
+
+	// e.g. TINYCODE_SLUG, TINYCODE_ID, TINYCODE_CONTEST
+	for k, v := range ctx.Filters.Map() {
+		name := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		env = append(env, fmt.Sprintf("TINYCODE_%s=%s", name, v))
+	}
+
+	return env
+}
+
+// writeRegion saves the submit region to a temporary file, named after the
+// solution so that formatters and linters recognize its language
+func (ctx *Context) writeRegion() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "tinycode-hook-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	name := "solution.txt"
+	if ctx.Path != "" {
+		name = "solution" + filepath.Ext(ctx.Path)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(ctx.Code), 0644); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return path, cleanup, nil
+}
+
+func shell(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+func (ctx *Context) run(stage string, hooks []Hook, stdin []byte, extraEnv []string, onFailure func(*Hook, error) error) error {
+	var applicable []Hook
+	for _, hook := range hooks {
+		if hook.AppliesTo(ctx.Lang) {
+			applicable = append(applicable, hook)
+		}
+	}
+
+	if len(applicable) == 0 {
+		return nil
+	}
+
+	regionFile, cleanup, err := ctx.writeRegion()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env := append(ctx.env(stage, regionFile), extraEnv...)
+
+	for _, hook := range applicable {
+		log.Printf("running %s hook: %s", stage, hook.String())
+
+		cmd := shell(hook.Run)
+		cmd.Env = env
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if ctx.Path != "" {
+			cmd.Dir = filepath.Dir(ctx.Path)
+		}
+
+		if err := cmd.Run(); err != nil {
+			if err := onFailure(&hook, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunPreSubmit runs the pre-submit hooks applying to the language of the
+// submission, stopping at the first one which fails. If force is set, failures
+// are reported but do not stop the submission.
+func (ctx *Context) RunPreSubmit(config Config, force bool) error {
+	return ctx.run(PreSubmit, config.PreSubmit, nil, nil, func(hook *Hook, err error) error {
+		if force {
+			fmt.Fprintf(os.Stderr, "tinycode: pre-submit hook %s failed (%s), submitting anyway\n", hook.String(), err)
+			return nil
+		}
+		return fmt.Errorf("pre-submit hook %s failed (%s): fix it or use --force to submit anyway", hook.String(), err)
+	})
+}
+
+// RunPostSubmit runs the post-submit hooks applying to the language of the
+// submission, passing them the verdict as JSON on stdin (and whether it
+// succeeded in TINYCODE_SUCCEEDED). Failures are only reported.
+func (ctx *Context) RunPostSubmit(config Config, verdict provider.Verdict) error {
+	var buf bytes.Buffer
+	if err := writeJson(&buf, verdict); err != nil {
+		return err
+	}
+
+	succeeded := fmt.Sprintf("TINYCODE_SUCCEEDED=%t", verdict.Succeeded)
+	return ctx.run(PostSubmit, config.PostSubmit, buf.Bytes(), []string{succeeded}, func(hook *Hook, err error) error {
+		fmt.Fprintf(os.Stderr, "tinycode: post-submit hook %s failed: %s\n", hook.String(), err)
+		return nil
+	})
+}
+
+func writeJson(writer io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
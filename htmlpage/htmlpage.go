@@ -0,0 +1,45 @@
+// Package htmlpage lays out the HTML reports of tinycode, which are single
+// files with their styles inline so that they can be shared as is
+package htmlpage
+
+import (
+	"html/template"
+	"io"
+)
+
+// layout is the page around a report, which defines the "title", "style" and
+// "body" templates
+const layout = `{{define "page"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{template "title" .}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 52em; margin: 2em auto; padding: 0 1em; color: #222; }
+h1 { font-size: 1.6em; margin-bottom: 0; }
+.subtitle { color: #777; margin-top: .3em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .3em .5em; }
+th { color: #777; font-weight: normal; font-size: .85em; }
+td.count { text-align: right; width: 4em; font-variant-numeric: tabular-nums; }
+{{template "style" .}}</style>
+</head>
+<body>
+{{template "body" .}}
+</body>
+</html>
+{{end}}`
+
+// Render writes the report defined by definitions as a page, with data
+func Render(writer io.Writer, name string, functions template.FuncMap, definitions string, data interface{}) error {
+	tmpl, err := template.New(name).Funcs(functions).Parse(layout)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmpl.Parse(definitions); err != nil {
+		return err
+	}
+
+	return tmpl.ExecuteTemplate(writer, "page", data)
+}
@@ -0,0 +1,158 @@
+// Package jsonrpc implements JSON-RPC 2.0 over a stream, with messages framed
+// by Content-Length headers as in the Language Server Protocol
+package jsonrpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const Version = "2.0"
+
+// Error codes defined by the specification
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+)
+
+type Request struct {
+	Version string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"` // nil for notifications
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+func (req *Request) IsNotification() bool {
+	return req.Id == nil
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", err.Message, err.Code)
+}
+
+func NewError(code int, format string, args ...interface{}) *Error {
+	return &Error{code, fmt.Sprintf(format, args...)}
+}
+
+type response struct {
+	Version string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+type notification struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Conn reads requests from and writes responses to a pair of streams. Writes
+// are safe to make concurrently.
+type Conn struct {
+	reader *bufio.Reader
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewConn(reader io.Reader, writer io.Writer) *Conn {
+	return &Conn{reader: bufio.NewReader(reader), writer: writer}
+}
+
+// Read returns the next request. Malformed requests are reported as an *Error,
+// after which reading can go on; any other error is final.
+func (conn *Conn) Read() (*Request, error) {
+	length := -1
+	for {
+		line, err := conn.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed header: %s", line)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(conn.reader, body); err != nil {
+		return nil, err
+	}
+
+	req := Request{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, NewError(ParseError, "could not parse request: %s", err)
+	}
+
+	if req.Version != Version || req.Method == "" {
+		return nil, NewError(InvalidRequest, "not a JSON-RPC %s request", Version)
+	}
+
+	return &req, nil
+}
+
+func (conn *Conn) write(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if _, err := fmt.Fprintf(conn.writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = conn.writer.Write(body)
+	return err
+}
+
+func (conn *Conn) Reply(id *json.RawMessage, result interface{}) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return conn.write(response{Version: Version, Id: id, Result: encoded})
+}
+
+// ReplyError answers a request with an error; id is nil if the request could
+// not be read
+func (conn *Conn) ReplyError(id *json.RawMessage, err *Error) error {
+	return conn.write(response{Version: Version, Id: id, Error: err})
+}
+
+func (conn *Conn) Notify(method string, params interface{}) error {
+	return conn.write(notification{Version: Version, Method: method, Params: params})
+}
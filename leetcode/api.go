@@ -1,9 +1,12 @@
 package leetcode
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/brokad/tinycode/provider"
+	"log"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -80,50 +83,132 @@ func (res *CheckResponse) Statistics() provider.SubmissionStatistics {
 	return stats
 }
 
+// passed counts the testcases the submission passed, if known
+func (res *CheckResponse) passed() string {
+	if res.TotalTestCases == 0 {
+		return ""
+	}
+	return fmt.Sprintf("passed %d/%d testcases", res.TotalCorrect, res.TotalTestCases)
+}
+
+// memory is how much memory the submission used, if known
+func (res *CheckResponse) memory() string {
+	if res.StatusMemory != "" && res.StatusMemory != "N/A" {
+		return res.StatusMemory
+	} else if res.Memory > 0 {
+		return fmt.Sprintf("%.1f MB", float64(res.Memory)/(1<<20))
+	}
+	return ""
+}
+
+// header joins the pass count and the facts which are known
+func (res *CheckResponse) header(facts ...string) string {
+	var parts []string
+	if passed := res.passed(); passed != "" {
+		parts = append(parts, passed)
+	}
+	for _, fact := range facts {
+		if fact != "" {
+			parts = append(parts, fact)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (res *CheckResponse) lastTestCase() string {
+	return strings.ReplaceAll(res.LastTestCase, "\n", ", ")
+}
+
+// outputs shows the expected output next to the actual one if either spans
+// several lines, or one after the other otherwise
+func (res *CheckResponse) outputs(expected string, got string) string {
+	if provider.IsMultiline(expected, got) {
+		return provider.SideBySide(expected, got)
+	}
+	return fmt.Sprintf("expected: %s\ngot: %s\n", expected, got)
+}
+
 func (res *CheckResponse) ErrorReport() *provider.ErrorReport {
 	if res.HasSucceeded() {
 		return nil
-	} else {
-		var err provider.ErrorReport
-		switch res.StatusCode {
-		case RuntimeError:
-			err = provider.NewErrorReport(
-				"runtime error",
-				res.RuntimeError,
-				fmt.Sprintf("last test case: %s", strings.ReplaceAll(res.LastTestCase, "\n", ", ")),
-				fmt.Sprintf("expected output: %s\n\nruntime error: %s\n", res.ExpectedOutput, res.FullRuntimeError),
-			)
-		case CompileError:
-			err = provider.NewErrorReport(
-				"compile error",
-				res.CompileError,
-				"",
-				fmt.Sprintf("%s\n", res.FullCompileError),
-			)
-		case WrongAnswer:
-			err = provider.NewErrorReport(
-				"wrong answer",
-				"solution provided an invalid answer",
-				fmt.Sprintf("on input: %s", res.InputFormatted),
-				fmt.Sprintf("expected: %s\ngot: %s\n", res.ExpectedOutput, res.CodeOutput),
-			)
-		case TimeLimitExceeded:
-			err = provider.NewErrorReport(
-				"time limit exceeded",
-				"solution took too long",
-				fmt.Sprintf("solution took: %dms", res.ElapsedTime),
-				fmt.Sprintf("on input: %s\nexpected output: %s\n", strings.ReplaceAll(res.LastTestCase, "\n", ", "), res.ExpectedOutput),
-			)
-		default:
-			err = provider.NewErrorReport(
-				"unhandled",
-				fmt.Sprintf("%s (%d)", res.StatusMsg, res.StatusCode),
-				"raw output",
-				fmt.Sprintf("%v", res),
-			)
+	}
+
+	var memory, elapsed string
+	if mem := res.memory(); mem != "" {
+		memory = fmt.Sprintf("using %s", mem)
+	}
+	if res.ElapsedTime > 0 {
+		elapsed = fmt.Sprintf("in %dms", res.ElapsedTime)
+	}
+
+	var err provider.ErrorReport
+	switch res.StatusCode {
+	case Accepted, WrongAnswer:
+		input := res.InputFormatted
+		if input == "" {
+			input = res.lastTestCase()
+		}
+		err = provider.NewErrorReport(
+			"wrong answer",
+			"solution provided an invalid answer",
+			res.header(fmt.Sprintf("failed on input: %s", input)),
+			res.outputs(res.ExpectedOutput, res.CodeOutput),
+		)
+	case RuntimeError:
+		err = provider.NewErrorReport(
+			"runtime error",
+			res.RuntimeError,
+			res.header(fmt.Sprintf("last test case: %s", res.lastTestCase())),
+			fmt.Sprintf("expected output: %s\n\nruntime error: %s\n", res.ExpectedOutput, res.FullRuntimeError),
+		)
+	case CompileError:
+		err = provider.NewErrorReport(
+			"compile error",
+			res.CompileError,
+			"",
+			fmt.Sprintf("%s\n", res.FullCompileError),
+		)
+	case TimeLimitExceeded:
+		err = provider.NewErrorReport(
+			"time limit exceeded",
+			"solution took too long",
+			res.header(fmt.Sprintf("solution took: %dms", res.ElapsedTime)),
+			fmt.Sprintf("on input: %s\nexpected output: %s\n", res.lastTestCase(), res.ExpectedOutput),
+		)
+	case MemoryLimitExceeded:
+		err = provider.NewErrorReport(
+			"memory limit exceeded",
+			"solution used too much memory",
+			res.header(memory, elapsed),
+			fmt.Sprintf("on input: %s\nexpected output: %s\n", res.lastTestCase(), res.ExpectedOutput),
+		)
+	case OutputLimitExceeded:
+		err = provider.NewErrorReport(
+			"output limit exceeded",
+			"solution printed too much, check for leftover debugging output",
+			res.header(elapsed),
+			fmt.Sprintf("on input: %s\nexpected output: %s\n", res.lastTestCase(), res.ExpectedOutput),
+		)
+	case InternalError:
+		err = provider.NewErrorReport(
+			"internal error",
+			"the judge failed to run the solution, try submitting again",
+			res.header(),
+			fmt.Sprintf("%s (%d)\n", res.StatusMsg, res.StatusCode),
+		)
+	default:
+		var context string
+		if res.LastTestCase != "" {
+			context = fmt.Sprintf("on input: %s\nexpected output: %s\n", res.lastTestCase(), res.ExpectedOutput)
 		}
-		return &err
+		err = provider.NewErrorReport(
+			"unknown error",
+			fmt.Sprintf("%s (%d)", res.StatusMsg, res.StatusCode),
+			res.header(memory, elapsed),
+			context,
+		)
 	}
+	return &err
 }
 
 func (res *CheckResponse) HasSucceeded() bool {
@@ -134,21 +219,81 @@ func (res *CheckResponse) Identify() string {
 	return res.SubmissionId
 }
 
+func (res *CheckResponse) Class() provider.VerdictClass {
+	if res.HasSucceeded() {
+		return provider.Accepted
+	}
+
+	switch res.StatusCode {
+	case Accepted, WrongAnswer:
+		return provider.WrongAnswer
+	case CompileError:
+		return provider.CompileError
+	case RuntimeError:
+		return provider.RuntimeError
+	case TimeLimitExceeded:
+		return provider.TimeLimitExceeded
+	case MemoryLimitExceeded:
+		return provider.MemoryLimitExceeded
+	case OutputLimitExceeded:
+		return provider.OutputLimitExceeded
+	default:
+		return provider.OtherError
+	}
+}
+
 type CodeSnippet struct {
 	Lang     string `json:"lang"`
 	LangSlug string `json:"langSlug"`
 	Code     string `json:"code"`
 }
 
+type TopicTag struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func tagNames(tags []TopicTag) []string {
+	var output []string
+	for _, tag := range tags {
+		output = append(output, tag.Name)
+	}
+	return output
+}
+
+// tagSlugs are the slugs of tags by name, as filters of question lists take
+// them
+func tagSlugs(tags []TopicTag) map[string]string {
+	output := map[string]string{}
+	for _, tag := range tags {
+		output[tag.Name] = tag.Slug
+	}
+	return output
+}
+
 type QuestionData struct {
-	QuestionId   string        `json:"questionId"`
-	Title        string        `json:"title"`
-	TitleSlug    string        `json:"titleSlug"`
-	Difficulty   string        `json:"difficulty"`
-	Likes        uint64        `json:"likes"`
-	Dislikes     uint64        `json:"dislikes"`
-	Content      string        `json:"content"`
-	CodeSnippets []CodeSnippet `json:"codeSnippets"`
+	QuestionId       string        `json:"questionId"`
+	Title            string        `json:"title"`
+	TitleSlug        string        `json:"titleSlug"`
+	IsPaidOnly       bool          `json:"isPaidOnly"`
+	Difficulty       string        `json:"difficulty"`
+	Likes            uint64        `json:"likes"`
+	Dislikes         uint64        `json:"dislikes"`
+	Content          string        `json:"content"`
+	CodeSnippets     []CodeSnippet `json:"codeSnippets"`
+	ExampleTestcases string        `json:"exampleTestcases"`
+	MetaData         string        `json:"metaData"`
+	TopicTags        []TopicTag    `json:"topicTags"`
+	Hints            []string      `json:"hints"`            // in HTML
+	SimilarQuestions string        `json:"similarQuestions"` // JSON encoded
+	Stats            string        `json:"stats"`            // JSON encoded
+	CompanyTagStats  string        `json:"companyTagStats"`  // JSON encoded, leetcode.com only and null unless premium
+	SQLSchema        []string      `json:"mysqlSchemas"`     // statements creating the tables of database problems, and filling them with the examples
+
+	// leetcode.cn only
+	TranslatedTitle   string `json:"translatedTitle"`
+	TranslatedContent string `json:"translatedContent"`
+	translated        bool   // whether to use the translations, if any
 }
 
 type DifficultyFilter string
@@ -170,6 +315,17 @@ func ParseDifficulty(s string) (*DifficultyFilter, error) {
 	return &difficulty, nil
 }
 
+// ParseCategory checks s is one of the categories LeetCode sorts problems in,
+// empty meaning all of them
+func ParseCategory(s string) (string, error) {
+	switch s {
+	case "", "algorithms", "database", "shell", "concurrency", "pandas":
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown category: %s, must be one of: algorithms, database, shell, concurrency, pandas", s)
+	}
+}
+
 type StatusFilter string
 
 func ParseStatus(s string) (*StatusFilter, error) {
@@ -189,8 +345,156 @@ func ParseStatus(s string) (*StatusFilter, error) {
 	return &status, nil
 }
 
+func (data *QuestionData) examplesFilename() string {
+	return fmt.Sprintf("%s.in", data.TitleSlug)
+}
+
+// sqliteSchema rewrites the schema of a database problem, which is written for
+// MySQL, into a script sqlite3 can run
+func (data *QuestionData) sqliteSchema() string {
+	var buf strings.Builder
+	for _, statement := range data.SQLSchema {
+		statement = strings.TrimSpace(statement)
+		// tables are created empty anyway, and sqlite has no TRUNCATE
+		if statement == "" || strings.HasPrefix(strings.ToLower(statement), "truncate") {
+			continue
+		}
+		statement = enumType.ReplaceAllString(statement, "text")
+		buf.WriteString(strings.TrimSuffix(statement, ";"))
+		buf.WriteString(";\n")
+	}
+	return buf.String()
+}
+
+var enumType = regexp.MustCompile(`(?i)\benum\s*\([^)]*\)`)
+
 func (data *QuestionData) Files() (map[string]string, error) {
-	return map[string]string{}, nil
+	if len(data.SQLSchema) != 0 {
+		// the examples are in the schema, which is the input of local runs
+		return map[string]string{
+			data.examplesFilename(): data.sqliteSchema(),
+		}, nil
+	}
+
+	if data.ExampleTestcases == "" {
+		return map[string]string{}, nil
+	}
+	return map[string]string{
+		data.examplesFilename(): fmt.Sprintf("%s\n", data.ExampleTestcases),
+	}, nil
+}
+
+func (data *QuestionData) title() string {
+	if data.translated && data.TranslatedTitle != "" {
+		return data.TranslatedTitle
+	}
+	return data.Title
+}
+
+func (data *QuestionData) Details() provider.ChallengeDetails {
+	return provider.ChallengeDetails{
+		Title:      data.title(),
+		Difficulty: data.Difficulty,
+		Tags:       tagNames(data.TopicTags),
+		TagSlugs:   tagSlugs(data.TopicTags),
+	}
+}
+
+type SimilarQuestion struct {
+	Title           string `json:"title"`
+	TranslatedTitle string `json:"translatedTitle"`
+	TitleSlug       string `json:"titleSlug"`
+	Difficulty      string `json:"difficulty"`
+}
+
+type QuestionStats struct {
+	TotalAccepted   string `json:"totalAccepted"`
+	TotalSubmission string `json:"totalSubmission"`
+	AcRate          string `json:"acRate"`
+}
+
+type CompanyTag struct {
+	Name             string `json:"name"`
+	Slug             string `json:"slug"`
+	TimesEncountered uint64 `json:"timesEncountered"`
+}
+
+// companies are the names of the companies which asked the question, most
+// frequent first, merged over the periods LeetCode groups them by
+func (data *QuestionData) companies() ([]string, error) {
+	if data.CompanyTagStats == "" {
+		return nil, nil
+	}
+
+	var periods map[string][]CompanyTag
+	if err := json.Unmarshal([]byte(data.CompanyTagStats), &periods); err != nil {
+		return nil, err
+	}
+
+	times := map[string]uint64{}
+	for _, tags := range periods {
+		for _, tag := range tags {
+			times[tag.Name] += tag.TimesEncountered
+		}
+	}
+
+	var output []string
+	for name := range times {
+		output = append(output, name)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if times[output[i]] != times[output[j]] {
+			return times[output[i]] > times[output[j]]
+		}
+		return output[i] < output[j]
+	})
+	return output, nil
+}
+
+func (data *QuestionData) Annotations() provider.Annotations {
+	output := provider.Annotations{
+		Tags: tagNames(data.TopicTags),
+	}
+
+	for _, hint := range data.Hints {
+		output.Hints = append(output.Hints, provider.HtmlToText(hint))
+	}
+
+	if data.SimilarQuestions != "" {
+		var similar []SimilarQuestion
+		if err := json.Unmarshal([]byte(data.SimilarQuestions), &similar); err != nil {
+			log.Printf("could not parse similar questions: %s", err)
+		}
+		for _, question := range similar {
+			title := question.Title
+			if data.translated && question.TranslatedTitle != "" {
+				title = question.TranslatedTitle
+			}
+			output.Similar = append(output.Similar, provider.SimilarChallenge{
+				Title:      title,
+				Slug:       question.TitleSlug,
+				Difficulty: question.Difficulty,
+			})
+		}
+	}
+
+	if data.Stats != "" {
+		var stats QuestionStats
+		if err := json.Unmarshal([]byte(data.Stats), &stats); err != nil {
+			log.Printf("could not parse question stats: %s", err)
+		}
+		output.AcceptanceRate = stats.AcRate
+		output.Accepted = stats.TotalAccepted
+		output.Submissions = stats.TotalSubmission
+	}
+
+	companies, err := data.companies()
+	if err != nil {
+		log.Printf("could not parse company tags: %s", err)
+	}
+	output.Companies = companies
+
+	return output
 }
 
 func (data *QuestionData) Identify() provider.Filters {
@@ -222,20 +526,69 @@ type Filters struct {
 	Difficulty DifficultyFilter `json:"difficulty,omitempty"`
 	Status     StatusFilter     `json:"status,omitempty"`
 	Tags       []string         `json:"tags,omitempty"`
+	// false to leave out paid-only problems, true to only keep them
+	PremiumOnly *bool `json:"premiumOnly,omitempty"`
 }
 
-func stripHtml(s string) string {
-	re := regexp.MustCompile("<\\/?[^>]*>")
-	output := re.ReplaceAllString(s, "")
+// ParseFilters extracts the difficulty, status and tags filters LeetCode
+// understands from filters
+func ParseFilters(filters provider.Filters) (*Filters, error) {
+	difficulty, err := ParseDifficulty(filters.GetFilterOrDefault("difficulty"))
+	if err != nil {
+		return nil, err
+	}
 
-	replacer := strings.NewReplacer(
-		"&nbsp;", " ",
-		"&lt;", "<",
-		"&gt;", ">",
-	)
-	output = replacer.Replace(output)
+	status, err := ParseStatus(filters.GetFilterOrDefault("status"))
+	if err != nil {
+		return nil, err
+	}
 
-	return output
+	var tags []string
+	if tagsStr := filters.GetFilterOrDefault("tags"); tagsStr != "" {
+		for _, tag := range strings.Split(tagsStr, ",") {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+	}
+
+	return &Filters{Difficulty: *difficulty, Status: *status, Tags: tags}, nil
+}
+
+type QuestionSummary struct {
+	QuestionId         string     `json:"questionId"`
+	QuestionFrontendId string     `json:"questionFrontendId"`
+	Title              string     `json:"title"`
+	TitleSlug          string     `json:"titleSlug"`
+	Difficulty         string     `json:"difficulty"`
+	Status             string     `json:"status"` // "ac", "notac" or empty
+	IsPaidOnly         bool       `json:"isPaidOnly"`
+	TopicTags          []TopicTag `json:"topicTags"`
+}
+
+func (question *QuestionSummary) Summarize() provider.ChallengeSummary {
+	var filters provider.Filters
+	_ = filters.AddFilter("slug", question.TitleSlug)
+	_ = filters.AddFilter("id", question.QuestionId)
+
+	summary := provider.ChallengeSummary{
+		Filters: filters,
+		Details: provider.ChallengeDetails{
+			Title:      question.Title,
+			Difficulty: question.Difficulty,
+			Tags:       tagNames(question.TopicTags),
+			TagSlugs:   tagSlugs(question.TopicTags),
+		},
+	}
+
+	switch question.Status {
+	case "ac":
+		summary.Status = provider.Solved
+	case "notac":
+		summary.Status = provider.Attempted
+	default:
+		summary.Status = provider.Todo
+	}
+
+	return summary
 }
 
 func (data *QuestionData) Snippet(lang provider.Lang) (string, error) {
@@ -254,5 +607,90 @@ func (data *QuestionData) Snippet(lang provider.Lang) (string, error) {
 }
 
 func (data *QuestionData) Prompt() string {
-	return stripHtml(data.Content)
+	if data.translated && data.TranslatedContent != "" {
+		return provider.HtmlToText(data.TranslatedContent)
+	}
+	return provider.HtmlToText(data.Content)
+}
+
+type DifficultyCount struct {
+	Difficulty  string `json:"difficulty"`
+	Count       uint64 `json:"count"`
+	Submissions uint64 `json:"submissions"`
+}
+
+type TagCount struct {
+	TagName        string `json:"tagName"`
+	TagSlug        string `json:"tagSlug"`
+	ProblemsSolved uint64 `json:"problemsSolved"`
+}
+
+type LanguageCount struct {
+	LanguageName   string `json:"languageName"`
+	ProblemsSolved uint64 `json:"problemsSolved"`
+}
+
+type MatchedUser struct {
+	Username          string `json:"username"`
+	SubmitStatsGlobal struct {
+		AcSubmissionNum    []DifficultyCount `json:"acSubmissionNum"`
+		TotalSubmissionNum []DifficultyCount `json:"totalSubmissionNum"`
+	} `json:"submitStatsGlobal"`
+	TagProblemCounts struct {
+		Advanced     []TagCount `json:"advanced"`
+		Intermediate []TagCount `json:"intermediate"`
+		Fundamental  []TagCount `json:"fundamental"`
+	} `json:"tagProblemCounts"`
+	LanguageProblemCount []LanguageCount `json:"languageProblemCount"`
+}
+
+type QuestionProgress struct {
+	NumAcceptedQuestions  []DifficultyCount `json:"numAcceptedQuestions"`
+	NumFailedQuestions    []DifficultyCount `json:"numFailedQuestions"`
+	NumUntouchedQuestions []DifficultyCount `json:"numUntouchedQuestions"`
+}
+
+func byDifficulty(counts []DifficultyCount) map[string]uint64 {
+	output := map[string]uint64{}
+	for _, count := range counts {
+		output[strings.ToLower(count.Difficulty)] = count.Count
+	}
+	return output
+}
+
+func (user *MatchedUser) Profile(progress *QuestionProgress) *provider.Profile {
+	profile := provider.Profile{
+		Username:  user.Username,
+		Solved:    byDifficulty(progress.NumAcceptedQuestions),
+		Attempted: byDifficulty(progress.NumFailedQuestions),
+		Untouched: byDifficulty(progress.NumUntouchedQuestions),
+		Tags:      map[string]uint64{},
+		TagSlugs:  map[string]string{},
+		Langs:     map[string]uint64{},
+	}
+
+	for _, count := range user.SubmitStatsGlobal.AcSubmissionNum {
+		if count.Difficulty == "All" {
+			profile.Accepted = count.Submissions
+		}
+	}
+	for _, count := range user.SubmitStatsGlobal.TotalSubmissionNum {
+		if count.Difficulty == "All" {
+			profile.Submissions = count.Submissions
+		}
+	}
+
+	tags := user.TagProblemCounts
+	for _, group := range [][]TagCount{tags.Fundamental, tags.Intermediate, tags.Advanced} {
+		for _, tag := range group {
+			profile.Tags[tag.TagName] = tag.ProblemsSolved
+			profile.TagSlugs[tag.TagName] = tag.TagSlug
+		}
+	}
+
+	for _, lang := range user.LanguageProblemCount {
+		profile.Langs[lang.LanguageName] = lang.ProblemsSolved
+	}
+
+	return &profile
 }
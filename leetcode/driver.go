@@ -0,0 +1,310 @@
+package leetcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/iancoleman/strcase"
+	"strings"
+)
+
+type MetaDataParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type MetaDataReturn struct {
+	Type string `json:"type"`
+}
+
+type MetaDataOutput struct {
+	ParamIndex int `json:"paramindex"`
+}
+
+type MetaData struct {
+	Name         string          `json:"name"`
+	Params       []MetaDataParam `json:"params"`
+	Return       MetaDataReturn  `json:"return"`
+	Output       *MetaDataOutput `json:"output"`
+	SystemDesign bool            `json:"systemdesign"`
+	Manual       bool            `json:"manual"`
+}
+
+func ParseMetaData(s string) (*MetaData, error) {
+	var metaData MetaData
+	if err := json.Unmarshal([]byte(s), &metaData); err != nil {
+		return nil, fmt.Errorf("could not parse question metadata: %s", err)
+	}
+	return &metaData, nil
+}
+
+// valueType is a LeetCode parameter type such as `integer[]`, `list<string>`
+// or `TreeNode`, flattened into its scalar kind and its nesting depth
+type valueType struct {
+	kind  string
+	depth int
+}
+
+func parseValueType(s string) (*valueType, error) {
+	var depth int
+	for {
+		if strings.HasPrefix(s, "list<") && strings.HasSuffix(s, ">") {
+			s = s[len("list<") : len(s)-1]
+		} else if strings.HasSuffix(s, "[]") {
+			s = s[:len(s)-2]
+		} else {
+			break
+		}
+		depth++
+	}
+
+	switch s {
+	case "integer", "long", "double", "boolean", "string", "character":
+		return &valueType{s, depth}, nil
+	case "ListNode", "TreeNode":
+		if depth > 1 {
+			break
+		}
+		return &valueType{s, depth}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported parameter type: %s", s)
+}
+
+func (t *valueType) render(scalars map[string]string, wrap func(string) string) string {
+	output := scalars[t.kind]
+	for i := 0; i < t.depth; i++ {
+		output = wrap(output)
+	}
+	return output
+}
+
+type signature struct {
+	name   string
+	params []valueType
+	result *valueType
+	output int // index of the param holding the result of void methods
+}
+
+func (metaData *MetaData) signature() (*signature, error) {
+	if metaData.SystemDesign || metaData.Manual {
+		return nil, fmt.Errorf("design problems are not supported")
+	}
+
+	if metaData.Name == "" {
+		return nil, fmt.Errorf("question metadata does not name a method")
+	}
+
+	if len(metaData.Params) == 0 {
+		return nil, fmt.Errorf("methods without parameters are not supported")
+	}
+
+	sig := signature{name: metaData.Name, output: -1}
+
+	for _, param := range metaData.Params {
+		parsed, err := parseValueType(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		sig.params = append(sig.params, *parsed)
+	}
+
+	if metaData.Return.Type == "void" {
+		if metaData.Output == nil || metaData.Output.ParamIndex >= len(sig.params) {
+			return nil, fmt.Errorf("void method without an output parameter")
+		}
+		sig.output = metaData.Output.ParamIndex
+	} else {
+		parsed, err := parseValueType(metaData.Return.Type)
+		if err != nil {
+			return nil, err
+		}
+		sig.result = parsed
+	}
+
+	return &sig, nil
+}
+
+func (data *QuestionData) Driver(lang provider.Lang) (string, string, error) {
+	metaData, err := ParseMetaData(data.MetaData)
+	if err != nil {
+		return "", "", err
+	}
+
+	sig, err := metaData.signature()
+	if err != nil {
+		return "", "", err
+	}
+
+	switch lang.String() {
+	case provider.Cpp, provider.Cpp14:
+		return cppPrelude, sig.cppEpilogue(), nil
+	case provider.Python3, provider.Pypy3:
+		return pythonPrelude, sig.pythonEpilogue(), nil
+	case provider.Golang:
+		return goPrelude, sig.goEpilogue(), nil
+	case provider.Java, provider.Java8, provider.Java15:
+		return sig.javaPrelude(), "", nil
+	case provider.Rust:
+		return rustPrelude, sig.rustEpilogue(), nil
+	default:
+		return "", "", fmt.Errorf("no driver code for lang: %s", lang.String())
+	}
+}
+
+func (sig *signature) cppEpilogue() string {
+	scalars := map[string]string{
+		"integer":   "int",
+		"long":      "long long",
+		"double":    "double",
+		"boolean":   "bool",
+		"string":    "string",
+		"character": "char",
+		"ListNode":  "ListNode *",
+		"TreeNode":  "TreeNode *",
+	}
+	wrap := func(s string) string {
+		return fmt.Sprintf("vector<%s>", s)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(cppRuntime)
+	buf.WriteString("\nint main() {\n")
+	buf.WriteString("    vector<string> lines = tinycode::readLines();\n")
+	fmt.Fprintf(&buf, "    for (size_t i = 0; i + %d <= lines.size(); i += %[1]d) {\n", len(sig.params))
+
+	var args []string
+	for idx, param := range sig.params {
+		fmt.Fprintf(&buf, "        auto arg%d = tinycode::Decode<%s>::from(tinycode::Parser(lines[i + %[1]d]).parse());\n", idx, param.render(scalars, wrap))
+		args = append(args, fmt.Sprintf("arg%d", idx))
+	}
+
+	call := fmt.Sprintf("Solution().%s(%s)", sig.name, strings.Join(args, ", "))
+	if sig.result != nil {
+		fmt.Fprintf(&buf, "        auto result = %s;\n", call)
+		buf.WriteString("        cout << tinycode::encode(result) << endl;\n")
+	} else {
+		fmt.Fprintf(&buf, "        %s;\n", call)
+		fmt.Fprintf(&buf, "        cout << tinycode::encode(arg%d) << endl;\n", sig.output)
+	}
+
+	buf.WriteString("    }\n    return 0;\n}\n")
+	return buf.String()
+}
+
+func (sig *signature) pythonEpilogue() string {
+	var params []string
+	for _, param := range sig.params {
+		params = append(params, fmt.Sprintf("(%q, %d)", param.kind, param.depth))
+	}
+
+	var buf strings.Builder
+	buf.WriteString(pythonRuntime)
+	buf.WriteString("\n\nif __name__ == \"__main__\":\n")
+	fmt.Fprintf(&buf, "    _params = [%s]\n", strings.Join(params, ", "))
+	buf.WriteString("    _lines = [line for line in sys.stdin.read().splitlines() if line.strip()]\n")
+	buf.WriteString("    for _i in range(0, len(_lines) - len(_params) + 1, len(_params)):\n")
+	buf.WriteString("        _args = [_decode(json.loads(_lines[_i + _j]), kind, depth) for _j, (kind, depth) in enumerate(_params)]\n")
+	fmt.Fprintf(&buf, "        _result = Solution().%s(*_args)\n", sig.name)
+	if sig.result != nil {
+		fmt.Fprintf(&buf, "        print(_encode(_result, %q))\n", sig.result.kind)
+	} else {
+		fmt.Fprintf(&buf, "        print(_encode(_args[%d], %q))\n", sig.output, sig.params[sig.output].kind)
+	}
+	return buf.String()
+}
+
+func (sig *signature) goEpilogue() string {
+	scalars := map[string]string{
+		"integer":   "int",
+		"long":      "int64",
+		"double":    "float64",
+		"boolean":   "bool",
+		"string":    "string",
+		"character": "byte",
+		"ListNode":  "*ListNode",
+		"TreeNode":  "*TreeNode",
+	}
+	wrap := func(s string) string {
+		return fmt.Sprintf("[]%s", s)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(goRuntime)
+	buf.WriteString("\nfunc main() {\n")
+	buf.WriteString("\tlines := tinycodeReadLines()\n")
+	fmt.Fprintf(&buf, "\tfor i := 0; i+%d <= len(lines); i += %[1]d {\n", len(sig.params))
+
+	var args []string
+	for idx, param := range sig.params {
+		fmt.Fprintf(&buf, "\t\tvar arg%d %s\n", idx, param.render(scalars, wrap))
+		fmt.Fprintf(&buf, "\t\ttinycodeDecode(lines[i+%d], &arg%[1]d)\n", idx)
+		args = append(args, fmt.Sprintf("arg%d", idx))
+	}
+
+	call := fmt.Sprintf("%s(%s)", sig.name, strings.Join(args, ", "))
+	if sig.result != nil {
+		fmt.Fprintf(&buf, "\t\tresult := %s\n", call)
+		buf.WriteString("\t\tfmt.Println(tinycodeEncode(reflect.ValueOf(result)))\n")
+	} else {
+		fmt.Fprintf(&buf, "\t\t%s\n", call)
+		fmt.Fprintf(&buf, "\t\tfmt.Println(tinycodeEncode(reflect.ValueOf(arg%d)))\n", sig.output)
+	}
+
+	buf.WriteString("\t}\n}\n")
+	return buf.String()
+}
+
+func (sig *signature) javaPrelude() string {
+	return strings.NewReplacer(
+		"{{method}}", sig.name,
+		"{{output}}", fmt.Sprintf("%d", sig.output),
+	).Replace(javaRuntime)
+}
+
+func (sig *signature) rustEpilogue() string {
+	scalars := map[string]string{
+		"integer":   "i32",
+		"long":      "i64",
+		"double":    "f64",
+		"boolean":   "bool",
+		"string":    "String",
+		"character": "char",
+		"ListNode":  "Option<Box<ListNode>>",
+		"TreeNode":  "Option<std::rc::Rc<std::cell::RefCell<TreeNode>>>",
+	}
+	wrap := func(s string) string {
+		return fmt.Sprintf("Vec<%s>", s)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(rustRuntime)
+	buf.WriteString("\nfn main() {\n")
+	buf.WriteString("    use tinycode::{Decode, Encode};\n\n")
+	buf.WriteString("    let lines = tinycode::read_lines();\n")
+	fmt.Fprintf(&buf, "    for chunk in lines.chunks_exact(%d) {\n", len(sig.params))
+
+	var args []string
+	for idx, param := range sig.params {
+		mutable := ""
+		arg := fmt.Sprintf("arg%d", idx)
+		if idx == sig.output {
+			mutable = "mut "
+			arg = fmt.Sprintf("&mut %s", arg)
+		}
+		fmt.Fprintf(&buf, "        let %sarg%d: %s = Decode::decode(&tinycode::parse(&chunk[%[2]d]));\n", mutable, idx, param.render(scalars, wrap))
+		args = append(args, arg)
+	}
+
+	call := fmt.Sprintf("Solution::%s(%s)", strcase.ToSnake(sig.name), strings.Join(args, ", "))
+	if sig.result != nil {
+		fmt.Fprintf(&buf, "        let result = %s;\n", call)
+		buf.WriteString("        println!(\"{}\", result.encode());\n")
+	} else {
+		fmt.Fprintf(&buf, "        %s;\n", call)
+		fmt.Fprintf(&buf, "        println!(\"{}\", arg%d.encode());\n", sig.output)
+	}
+
+	buf.WriteString("    }\n}\n")
+	return buf.String()
+}
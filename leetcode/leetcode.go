@@ -5,23 +5,40 @@ import (
 	"github.com/brokad/tinycode/provider"
 	"log"
 	"net/url"
-	"strings"
 	"time"
 )
 
 type Client struct {
-	transport provider.TransportClient
+	transport  provider.TransportClient
+	site       Site
+	locale     string
+	premium    *bool // cached, see IsPremium
+	onProgress func(provider.Progress)
 }
 
 func NewClient(base *url.URL) *Client {
+	return NewSiteClient(base, Global)
+}
+
+// NewChinaClient returns a client of leetcode.cn
+func NewChinaClient(base *url.URL) *Client {
+	return NewSiteClient(base, China)
+}
+
+func NewSiteClient(base *url.URL, site Site) *Client {
 	transport := provider.NewTransportClient(*base)
-	return &Client{transport}
+	transport.GraphqlPath = site.GraphqlPath
+	return &Client{transport: transport, site: site}
+}
+
+func (client *Client) OnProgress(onProgress func(provider.Progress)) {
+	client.onProgress = onProgress
 }
 
 func (client *Client) Configure(config provider.BackendConfig) error {
 	cookies := map[string]string{
-		"csrftoken":        config.Csrf,
-		"LEETCODE_SESSION": config.Session,
+		client.site.CsrfCookie:    config.Csrf,
+		client.site.SessionCookie: config.Session,
 	}
 
 	if err := client.transport.SetCookies(cookies); err != nil {
@@ -30,6 +47,7 @@ func (client *Client) Configure(config provider.BackendConfig) error {
 
 	client.transport.CsrfToken = config.Csrf
 	client.transport.CsrfTokenHeader = config.CsrfHeader
+	client.locale = config.Locale
 
 	return nil
 }
@@ -62,7 +80,179 @@ query globalData {
 	}
 }
 
-func (client *Client) GetRandomQuestionSlug(difficulty DifficultyFilter, status StatusFilter, tags []string, categorySlug string) (string, error) {
+// IsPremium is true if the signed in user subscribes to LeetCode premium, and
+// so can access paid-only problems
+func (client *Client) IsPremium() (bool, error) {
+	if client.premium != nil {
+		return *client.premium, nil
+	}
+
+	query := `
+query globalData {
+  userStatus {
+    isPremium
+  }
+}`
+	type UserStatus struct {
+		IsPremium bool `json:"isPremium"`
+	}
+
+	type QueryData struct {
+		UserStatus UserStatus `json:"userStatus"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("globalData", query, nil, &output); err != nil {
+		return false, err
+	}
+
+	client.premium = &output.Data.UserStatus.IsPremium
+	return *client.premium, nil
+}
+
+// hideLocked leaves the paid-only problems out of searches, unless the user can
+// access them or filters has premium=include
+func (client *Client) hideLocked(filters provider.Filters, questionFilters *Filters) {
+	if filters.GetFilterOrDefault("premium") == "include" {
+		return
+	}
+
+	if premium, err := client.IsPremium(); err != nil {
+		log.Printf("could not tell if the user is premium, hiding paid-only problems: %s", err)
+	} else if premium {
+		return
+	}
+
+	free := false
+	questionFilters.PremiumOnly = &free
+}
+
+func (client *Client) GetUsername() (string, error) {
+	query := `
+query globalData {
+  userStatus {
+    username
+  }
+}`
+	type UserStatus struct {
+		Username string `json:"username"`
+	}
+
+	type QueryData struct {
+		UserStatus UserStatus `json:"userStatus"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("globalData", query, nil, &output); err != nil {
+		return "", err
+	}
+
+	if username := output.Data.UserStatus.Username; username == "" {
+		return "", fmt.Errorf("not signed in")
+	} else {
+		return username, nil
+	}
+}
+
+func (client *Client) Profile() (*provider.Profile, error) {
+	if client.site.China {
+		return nil, client.unsupported("profiles")
+	}
+
+	username, err := client.GetUsername()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+query userProfile($username: String!, $userSlug: String!) {
+  matchedUser(username: $username) {
+    username
+    submitStatsGlobal {
+      acSubmissionNum {
+        difficulty
+        count
+        submissions
+      }
+      totalSubmissionNum {
+        difficulty
+        count
+        submissions
+      }
+    }
+    tagProblemCounts {
+      advanced {
+        tagName
+        tagSlug
+        problemsSolved
+      }
+      intermediate {
+        tagName
+        tagSlug
+        problemsSolved
+      }
+      fundamental {
+        tagName
+        tagSlug
+        problemsSolved
+      }
+    }
+    languageProblemCount {
+      languageName
+      problemsSolved
+    }
+  }
+  userProfileUserQuestionProgress(userSlug: $userSlug) {
+    numAcceptedQuestions {
+      difficulty
+      count
+    }
+    numFailedQuestions {
+      difficulty
+      count
+    }
+    numUntouchedQuestions {
+      difficulty
+      count
+    }
+  }
+}`
+
+	variables := map[string]string{
+		"username": username,
+		"userSlug": username,
+	}
+
+	type QueryData struct {
+		MatchedUser      MatchedUser      `json:"matchedUser"`
+		QuestionProgress QuestionProgress `json:"userProfileUserQuestionProgress"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("userProfile", query, variables, &output); err != nil {
+		return nil, err
+	}
+
+	return output.Data.MatchedUser.Profile(&output.Data.QuestionProgress), nil
+}
+
+func (client *Client) GetRandomQuestionSlug(filters Filters, categorySlug string) (string, error) {
+	if client.site.China {
+		return client.getChinaRandomQuestionSlug(filters, categorySlug)
+	}
+
 	query := `
 query randomQuestion($categorySlug: String, $filters: QuestionListFilterInput) {
   randomQuestion(categorySlug: $categorySlug, filters: $filters) {
@@ -70,12 +260,6 @@ query randomQuestion($categorySlug: String, $filters: QuestionListFilterInput) {
   }
 }`
 
-	filters := Filters{
-		difficulty,
-		status,
-		tags,
-	}
-
 	type Variables struct {
 		CategorySlug string  `json:"categorySlug"`
 		Filters      Filters `json:"filters"`
@@ -112,32 +296,21 @@ query randomQuestion($categorySlug: String, $filters: QuestionListFilterInput) {
 }
 
 func (client *Client) FindNextChallenge(filters provider.Filters) (provider.Filters, error) {
-	var err error
 	var output provider.Filters
 
-	difficultyStr := filters.GetFilterOrDefault("difficulty")
-	difficulty, err := ParseDifficulty(difficultyStr)
+	questionFilters, err := ParseFilters(filters)
 	if err != nil {
 		return output, err
 	}
 
-	statusStr := filters.GetFilterOrDefault("status")
-	status, err := ParseStatus(statusStr)
+	category, err := ParseCategory(filters.GetFilterOrDefault("category"))
 	if err != nil {
 		return output, err
 	}
 
-	var tags []string
-	tagsStr := filters.GetFilterOrDefault("tags")
+	client.hideLocked(filters, questionFilters)
 
-	if tagsStr != "" {
-		for _, tag := range strings.Split(tagsStr, ",") {
-			tag = strings.TrimSpace(tag)
-			tags = append(tags, tag)
-		}
-	}
-
-	questionSlug, err := client.GetRandomQuestionSlug(*difficulty, *status, tags, "")
+	questionSlug, err := client.GetRandomQuestionSlug(*questionFilters, category)
 	if err != nil {
 		return output, err
 	}
@@ -149,8 +322,89 @@ func (client *Client) FindNextChallenge(filters provider.Filters) (provider.Filt
 	}
 }
 
+func (client *Client) GetQuestionList(filters Filters, categorySlug string, skip uint64, limit uint64) ([]QuestionSummary, error) {
+	if client.site.China {
+		return client.getChinaQuestionList(filters, categorySlug, skip, limit)
+	}
+
+	query := `
+query problemsetQuestionList($categorySlug: String, $limit: Int, $skip: Int, $filters: QuestionListFilterInput) {
+  problemsetQuestionList: questionList(categorySlug: $categorySlug, limit: $limit, skip: $skip, filters: $filters) {
+    total: totalNum
+    questions: data {
+      questionId
+      questionFrontendId
+      title
+      titleSlug
+      difficulty
+      status
+      isPaidOnly
+      topicTags {
+        name
+        slug
+      }
+    }
+  }
+}`
+
+	type Variables struct {
+		CategorySlug string  `json:"categorySlug"`
+		Skip         uint64  `json:"skip"`
+		Limit        uint64  `json:"limit"`
+		Filters      Filters `json:"filters"`
+	}
+
+	variables := Variables{
+		categorySlug,
+		skip,
+		limit,
+		filters,
+	}
+
+	type QuestionList struct {
+		Total     uint64            `json:"total"`
+		Questions []QuestionSummary `json:"questions"`
+	}
+
+	type QueryData struct {
+		QuestionList QuestionList `json:"problemsetQuestionList"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("problemsetQuestionList", query, variables, &output); err != nil {
+		return nil, err
+	}
+
+	return output.Data.QuestionList.Questions, nil
+}
+
+func (client *Client) List(filters provider.Filters, offset uint64, limit uint64) ([]provider.ChallengeSummary, error) {
+	questionFilters, err := ParseFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := ParseCategory(filters.GetFilterOrDefault("category"))
+	if err != nil {
+		return nil, err
+	}
+
+	client.hideLocked(filters, questionFilters)
+
+	questions, err := client.GetQuestionList(*questionFilters, category, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeAll(questions), nil
+}
+
 func (client *Client) SubmitCode(questionId string, slug string, lang string, code string) (*SubmitResponse, error) {
-	submitPath, err := url.Parse(fmt.Sprintf("/problems/%s/submit/", slug))
+	submitPath, err := url.Parse(fmt.Sprintf(client.site.SubmitPath, slug))
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +453,7 @@ func (client *Client) Submit(filters provider.Filters, lang provider.Lang, code
 }
 
 func (client *Client) WaitUntilCompleteOrTimeOut(submissionId int64, timeOut time.Duration) (*CheckResponse, error) {
-	checkPath, err := url.Parse(fmt.Sprintf("/submissions/detail/%d/check/", submissionId))
+	checkPath, err := url.Parse(fmt.Sprintf(client.site.CheckPath, submissionId))
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +472,14 @@ func (client *Client) WaitUntilCompleteOrTimeOut(submissionId int64, timeOut tim
 			return &checkResp, nil
 		}
 
+		if client.onProgress != nil {
+			client.onProgress(provider.Progress{
+				Submission: fmt.Sprintf("%d", submissionId),
+				State:      string(checkResp.State),
+				Elapsed:    time.Since(start).Milliseconds(),
+			})
+		}
+
 		// Wait a bit before trying again
 		backoff *= 2
 		if time.Now().Add(backoff).Before(start.Add(timeOut)) {
@@ -231,13 +493,25 @@ func (client *Client) WaitUntilCompleteOrTimeOut(submissionId int64, timeOut tim
 }
 
 func (client *Client) GetQuestionData(titleSlug string) (*QuestionData, error) {
+	// only leetcode.cn has translations, and company tags are leetcode.com's
+	var translations string
+	if client.site.China {
+		translations = `
+    translatedTitle
+    translatedContent`
+	} else {
+		translations = `
+    companyTagStats`
+	}
+
 	query := `
 query questionData($titleSlug: String!) {
   question(titleSlug: $titleSlug) {
     questionId
     title
     titleSlug
-    content
+    isPaidOnly
+    content` + translations + `
     difficulty
     likes
     dislikes
@@ -251,6 +525,14 @@ query questionData($titleSlug: String!) {
     sampleTestCase
     metaData
     envInfo
+    topicTags {
+      name
+      slug
+    }
+    hints
+    similarQuestions
+    stats
+    mysqlSchemas
     __typename
   }
 }
@@ -274,17 +556,160 @@ query questionData($titleSlug: String!) {
 		return nil, err
 	}
 
+	res.Data.Question.translated = client.translated()
+
 	return &res.Data.Question, nil
 }
 
 func (client *Client) GetChallenge(filters provider.Filters) (provider.Challenge, error) {
-	if slug, err := filters.GetFilter("slug"); err == nil {
-		return client.GetQuestionData(slug)
-	} else {
+	slug, err := filters.GetFilter("slug")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := client.GetQuestionData(slug)
+	if err != nil {
 		return nil, err
 	}
+
+	// locked problems come without a statement nor snippets
+	if data.IsPaidOnly && (data.Content == "" || len(data.CodeSnippets) == 0) {
+		return nil, fmt.Errorf("%s is a premium problem: only LeetCode premium subscribers can check it out", slug)
+	}
+
+	return data, nil
 }
 
 func LocalizeLanguage(lang provider.Lang) (string, error) {
 	return lang.String(), nil
 }
+
+func summarizeAll(questions []QuestionSummary) []provider.ChallengeSummary {
+	var output []provider.ChallengeSummary
+	for _, question := range questions {
+		output = append(output, question.Summarize())
+	}
+	return output
+}
+
+func (client *Client) Favorites() ([]provider.ProblemList, error) {
+	if client.site.China {
+		return nil, client.unsupported("lists of favorites")
+	}
+
+	query := `
+query favoritesList {
+  favoritesLists {
+    allFavorites {
+      idHash
+      name
+      questions {
+        questionId
+        title
+        titleSlug
+      }
+    }
+  }
+}`
+
+	type Favorite struct {
+		IdHash    string            `json:"idHash"`
+		Name      string            `json:"name"`
+		Questions []QuestionSummary `json:"questions"`
+	}
+
+	type FavoritesLists struct {
+		AllFavorites []Favorite `json:"allFavorites"`
+	}
+
+	type QueryData struct {
+		FavoritesLists FavoritesLists `json:"favoritesLists"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("favoritesList", query, nil, &output); err != nil {
+		return nil, err
+	}
+
+	var lists []provider.ProblemList
+	for _, favorite := range output.Data.FavoritesLists.AllFavorites {
+		lists = append(lists, provider.ProblemList{
+			Slug:     favorite.IdHash,
+			Name:     favorite.Name,
+			Problems: summarizeAll(favorite.Questions),
+		})
+	}
+
+	return lists, nil
+}
+
+func (client *Client) StudyPlan(slug string) (*provider.ProblemList, error) {
+	query := `
+query studyPlanDetail($slug: String!) {
+  studyPlanV2Detail(planSlug: $slug) {
+    slug
+    name
+    planSubGroups {
+      name
+      questions {
+        title
+        titleSlug
+        difficulty
+        topicTags {
+          name
+          slug
+        }
+      }
+    }
+  }
+}`
+
+	variables := map[string]string{
+		"slug": slug,
+	}
+
+	type SubGroup struct {
+		Name      string            `json:"name"`
+		Questions []QuestionSummary `json:"questions"`
+	}
+
+	type StudyPlan struct {
+		Slug          string     `json:"slug"`
+		Name          string     `json:"name"`
+		PlanSubGroups []SubGroup `json:"planSubGroups"`
+	}
+
+	type QueryData struct {
+		StudyPlan *StudyPlan `json:"studyPlanV2Detail"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("studyPlanDetail", query, variables, &output); err != nil {
+		return nil, err
+	}
+
+	plan := output.Data.StudyPlan
+	if plan == nil {
+		return nil, fmt.Errorf("no such study plan: %s", slug)
+	}
+
+	list := provider.ProblemList{
+		Slug: plan.Slug,
+		Name: plan.Name,
+	}
+	for _, group := range plan.PlanSubGroups {
+		for _, question := range group.Questions {
+			list.Problems = append(list.Problems, question.Summarize())
+		}
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,1021 @@
+package leetcode
+
+// Driver code shared by every question, by language. The drivers read
+// LeetCode-formatted testcases (one parameter per line, as in the
+// `exampleTestcases` of a question) from stdin and print one result per line.
+
+const cppPrelude = `#include <bits/stdc++.h>
+using namespace std;
+
+struct ListNode {
+    int val;
+    ListNode *next;
+    ListNode() : val(0), next(nullptr) {}
+    ListNode(int x) : val(x), next(nullptr) {}
+    ListNode(int x, ListNode *next) : val(x), next(next) {}
+};
+
+struct TreeNode {
+    int val;
+    TreeNode *left;
+    TreeNode *right;
+    TreeNode() : val(0), left(nullptr), right(nullptr) {}
+    TreeNode(int x) : val(x), left(nullptr), right(nullptr) {}
+    TreeNode(int x, TreeNode *left, TreeNode *right) : val(x), left(left), right(right) {}
+};
+`
+
+const cppRuntime = `namespace tinycode {
+
+struct Json {
+    enum Kind { Null, Bool, Number, String, Array } kind = Null;
+    bool boolean = false;
+    string text;
+    vector<Json> items;
+};
+
+struct Parser {
+    const string &s;
+    size_t pos = 0;
+
+    explicit Parser(const string &s) : s(s) {}
+
+    void skip() {
+        while (pos < s.size() && isspace((unsigned char) s[pos])) pos++;
+    }
+
+    Json parse() {
+        skip();
+        Json value;
+        if (pos >= s.size()) return value;
+        char c = s[pos];
+        if (c == '[') {
+            value.kind = Json::Array;
+            pos++;
+            skip();
+            if (pos < s.size() && s[pos] == ']') {
+                pos++;
+                return value;
+            }
+            while (pos < s.size()) {
+                value.items.push_back(parse());
+                skip();
+                if (s[pos++] != ',') break;
+            }
+        } else if (c == '"') {
+            value.kind = Json::String;
+            pos++;
+            while (pos < s.size() && s[pos] != '"') {
+                if (s[pos] == '\\') {
+                    pos++;
+                    value.text += s[pos] == 'n' ? '\n' : s[pos] == 't' ? '\t' : s[pos];
+                } else {
+                    value.text += s[pos];
+                }
+                pos++;
+            }
+            pos++;
+        } else if (s.compare(pos, 4, "true") == 0) {
+            value.kind = Json::Bool;
+            value.boolean = true;
+            pos += 4;
+        } else if (s.compare(pos, 5, "false") == 0) {
+            value.kind = Json::Bool;
+            pos += 5;
+        } else if (s.compare(pos, 4, "null") == 0) {
+            pos += 4;
+        } else {
+            value.kind = Json::Number;
+            while (pos < s.size() && (isdigit((unsigned char) s[pos]) || strchr("+-.eE", s[pos]))) value.text += s[pos++];
+        }
+        return value;
+    }
+};
+
+template <typename T> struct Decode;
+
+template <> struct Decode<int> {
+    static int from(const Json &v) { return stoi(v.text); }
+};
+
+template <> struct Decode<long long> {
+    static long long from(const Json &v) { return stoll(v.text); }
+};
+
+template <> struct Decode<double> {
+    static double from(const Json &v) { return stod(v.text); }
+};
+
+template <> struct Decode<bool> {
+    static bool from(const Json &v) { return v.boolean; }
+};
+
+template <> struct Decode<string> {
+    static string from(const Json &v) { return v.text; }
+};
+
+template <> struct Decode<char> {
+    static char from(const Json &v) { return v.text.empty() ? '\0' : v.text[0]; }
+};
+
+template <typename T> struct Decode<vector<T>> {
+    static vector<T> from(const Json &v) {
+        vector<T> output;
+        for (const Json &item : v.items) output.push_back(Decode<T>::from(item));
+        return output;
+    }
+};
+
+template <> struct Decode<ListNode *> {
+    static ListNode *from(const Json &v) {
+        ListNode *head = nullptr;
+        for (auto it = v.items.rbegin(); it != v.items.rend(); it++) head = new ListNode(stoi(it->text), head);
+        return head;
+    }
+};
+
+template <> struct Decode<TreeNode *> {
+    static TreeNode *from(const Json &v) {
+        if (v.items.empty() || v.items[0].kind == Json::Null) return nullptr;
+        TreeNode *root = new TreeNode(stoi(v.items[0].text));
+        queue<TreeNode *> nodes;
+        nodes.push(root);
+        size_t i = 1;
+        while (!nodes.empty() && i < v.items.size()) {
+            TreeNode *node = nodes.front();
+            nodes.pop();
+            if (i < v.items.size() && v.items[i].kind != Json::Null) {
+                node->left = new TreeNode(stoi(v.items[i].text));
+                nodes.push(node->left);
+            }
+            i++;
+            if (i < v.items.size() && v.items[i].kind != Json::Null) {
+                node->right = new TreeNode(stoi(v.items[i].text));
+                nodes.push(node->right);
+            }
+            i++;
+        }
+        return root;
+    }
+};
+
+string encode(int v) { return to_string(v); }
+
+string encode(long long v) { return to_string(v); }
+
+string encode(double v) {
+    char buf[64];
+    snprintf(buf, sizeof buf, "%.5f", v);
+    return buf;
+}
+
+string encode(bool v) { return v ? "true" : "false"; }
+
+string encode(const string &v) {
+    string output = "\"";
+    for (char c : v) {
+        if (c == '"' || c == '\\') output += '\\';
+        output += c;
+    }
+    return output + "\"";
+}
+
+string encode(char v) { return encode(string(1, v)); }
+
+string encode(ListNode *node) {
+    string output = "[";
+    for (; node != nullptr; node = node->next) {
+        output += to_string(node->val);
+        if (node->next != nullptr) output += ",";
+    }
+    return output + "]";
+}
+
+string encode(TreeNode *root) {
+    vector<string> values;
+    queue<TreeNode *> nodes;
+    nodes.push(root);
+    while (!nodes.empty()) {
+        TreeNode *node = nodes.front();
+        nodes.pop();
+        if (node == nullptr) {
+            values.push_back("null");
+        } else {
+            values.push_back(to_string(node->val));
+            nodes.push(node->left);
+            nodes.push(node->right);
+        }
+    }
+    while (!values.empty() && values.back() == "null") values.pop_back();
+    string output = "[";
+    for (size_t i = 0; i < values.size(); i++) {
+        if (i > 0) output += ",";
+        output += values[i];
+    }
+    return output + "]";
+}
+
+template <typename T> string encode(const vector<T> &v) {
+    string output = "[";
+    for (size_t i = 0; i < v.size(); i++) {
+        if (i > 0) output += ",";
+        output += encode(static_cast<T>(v[i]));
+    }
+    return output + "]";
+}
+
+vector<string> readLines() {
+    vector<string> lines;
+    string line;
+    while (getline(cin, line)) {
+        if (line.find_first_not_of(" \t\r") != string::npos) lines.push_back(line);
+    }
+    return lines;
+}
+
+}
+`
+
+const pythonPrelude = `import json
+import sys
+from typing import *
+
+
+class ListNode:
+    def __init__(self, val=0, next=None):
+        self.val = val
+        self.next = next
+
+
+class TreeNode:
+    def __init__(self, val=0, left=None, right=None):
+        self.val = val
+        self.left = left
+        self.right = right
+`
+
+const pythonRuntime = `def _decode(value, kind, depth):
+    if depth > 0:
+        return [_decode(v, kind, depth - 1) for v in value]
+    if kind == "ListNode":
+        head = None
+        for v in reversed(value):
+            head = ListNode(v, head)
+        return head
+    if kind == "TreeNode":
+        if not value or value[0] is None:
+            return None
+        root = TreeNode(value[0])
+        queue, i = [root], 1
+        while queue and i < len(value):
+            node = queue.pop(0)
+            if i < len(value) and value[i] is not None:
+                node.left = TreeNode(value[i])
+                queue.append(node.left)
+            i += 1
+            if i < len(value) and value[i] is not None:
+                node.right = TreeNode(value[i])
+                queue.append(node.right)
+            i += 1
+        return root
+    return value
+
+
+def _encode(value, kind):
+    if value is None and kind in ("ListNode", "TreeNode"):
+        return "[]"
+    if isinstance(value, ListNode):
+        values = []
+        while value is not None:
+            values.append(value.val)
+            value = value.next
+        return _encode(values, "integer")
+    if isinstance(value, TreeNode):
+        values, queue = [], [value]
+        while queue:
+            node = queue.pop(0)
+            if node is None:
+                values.append(None)
+            else:
+                values.append(node.val)
+                queue.append(node.left)
+                queue.append(node.right)
+        while values and values[-1] is None:
+            values.pop()
+        return _encode(values, "integer")
+    if isinstance(value, float):
+        return "%.5f" % value
+    if isinstance(value, (list, tuple)):
+        return "[" + ",".join(_encode(v, kind) for v in value) + "]"
+    return json.dumps(value)
+`
+
+const goPrelude = `package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LeetCode makes these packages available without importing them
+var _ = heap.Init
+var _ = math.MaxInt32
+var _ = sort.Ints
+var _ = strconv.Itoa
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+`
+
+const goRuntime = `func tinycodeReadLines() []string {
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func tinycodeDecode(line string, target interface{}) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		panic(fmt.Sprintf("invalid testcase: %s: %s", line, err))
+	}
+	tinycodeDecodeValue(raw, reflect.ValueOf(target).Elem())
+}
+
+func tinycodeDecodeValue(raw interface{}, target reflect.Value) {
+	switch target.Interface().(type) {
+	case *ListNode:
+		var head *ListNode
+		items := raw.([]interface{})
+		for i := len(items) - 1; i >= 0; i-- {
+			val, _ := items[i].(json.Number).Int64()
+			head = &ListNode{Val: int(val), Next: head}
+		}
+		target.Set(reflect.ValueOf(head))
+		return
+	case *TreeNode:
+		items := raw.([]interface{})
+		if len(items) == 0 || items[0] == nil {
+			return
+		}
+		newNode := func(item interface{}) *TreeNode {
+			val, _ := item.(json.Number).Int64()
+			return &TreeNode{Val: int(val)}
+		}
+		root := newNode(items[0])
+		queue := []*TreeNode{root}
+		for i := 1; len(queue) > 0 && i < len(items); i += 2 {
+			node := queue[0]
+			queue = queue[1:]
+			if items[i] != nil {
+				node.Left = newNode(items[i])
+				queue = append(queue, node.Left)
+			}
+			if i+1 < len(items) && items[i+1] != nil {
+				node.Right = newNode(items[i+1])
+				queue = append(queue, node.Right)
+			}
+		}
+		target.Set(reflect.ValueOf(root))
+		return
+	}
+
+	switch target.Kind() {
+	case reflect.Slice:
+		items := raw.([]interface{})
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			tinycodeDecodeValue(item, slice.Index(i))
+		}
+		target.Set(slice)
+	case reflect.Uint8:
+		target.SetUint(uint64(raw.(string)[0]))
+	case reflect.Int, reflect.Int64:
+		val, _ := raw.(json.Number).Int64()
+		target.SetInt(val)
+	case reflect.Float64:
+		val, _ := raw.(json.Number).Float64()
+		target.SetFloat(val)
+	case reflect.Bool:
+		target.SetBool(raw.(bool))
+	case reflect.String:
+		target.SetString(raw.(string))
+	}
+}
+
+func tinycodeEncode(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case *ListNode:
+		var values []string
+		for node := value; node != nil; node = node.Next {
+			values = append(values, strconv.Itoa(node.Val))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case *TreeNode:
+		var values []string
+		queue := []*TreeNode{value}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if node == nil {
+				values = append(values, "null")
+			} else {
+				values = append(values, strconv.Itoa(node.Val))
+				queue = append(queue, node.Left, node.Right)
+			}
+		}
+		for len(values) > 0 && values[len(values)-1] == "null" {
+			values = values[:len(values)-1]
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		var values []string
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, tinycodeEncode(v.Index(i)))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case reflect.Uint8:
+		return strconv.Quote(string(rune(v.Uint())))
+	case reflect.Float64:
+		return fmt.Sprintf("%.5f", v.Float())
+	default:
+		output, _ := json.Marshal(v.Interface())
+		return string(output)
+	}
+}
+`
+
+const javaRuntime = `import java.io.*;
+import java.lang.reflect.*;
+import java.util.*;
+
+class Main {
+    public static void main(String[] args) throws Exception {
+        List<String> lines = new ArrayList<>();
+        BufferedReader reader = new BufferedReader(new InputStreamReader(System.in));
+        for (String line = reader.readLine(); line != null; line = reader.readLine()) {
+            if (!line.trim().isEmpty()) lines.add(line);
+        }
+
+        Method method = null;
+        for (Method candidate : Solution.class.getDeclaredMethods()) {
+            if (candidate.getName().equals("{{method}}")) method = candidate;
+        }
+        method.setAccessible(true);
+
+        Type[] types = method.getGenericParameterTypes();
+        int output = {{output}};
+        for (int i = 0; i + types.length <= lines.size(); i += types.length) {
+            Object[] arguments = new Object[types.length];
+            for (int j = 0; j < types.length; j++) {
+                arguments[j] = decode(new Parser(lines.get(i + j)).parse(), types[j]);
+            }
+            Object result = method.invoke(new Solution(), arguments);
+            Class<?> resultType = method.getReturnType();
+            if (output >= 0) {
+                result = arguments[output];
+                resultType = method.getParameterTypes()[output];
+            }
+            if (result == null && (resultType == ListNode.class || resultType == TreeNode.class)) {
+                System.out.println("[]");
+            } else {
+                System.out.println(encode(result));
+            }
+        }
+    }
+
+    static Object decode(Object value, Type type) {
+        if (type instanceof ParameterizedType) {
+            Type item = ((ParameterizedType) type).getActualTypeArguments()[0];
+            List<Object> list = new ArrayList<>();
+            for (Object v : (List<?>) value) list.add(decode(v, item));
+            return list;
+        }
+        Class<?> cls = (Class<?>) type;
+        if (cls.isArray()) {
+            List<?> items = (List<?>) value;
+            Object array = Array.newInstance(cls.getComponentType(), items.size());
+            for (int i = 0; i < items.size(); i++) Array.set(array, i, decode(items.get(i), cls.getComponentType()));
+            return array;
+        }
+        if (cls == int.class || cls == Integer.class) return ((Number) value).intValue();
+        if (cls == long.class || cls == Long.class) return ((Number) value).longValue();
+        if (cls == double.class || cls == Double.class) return ((Number) value).doubleValue();
+        if (cls == boolean.class || cls == Boolean.class) return value;
+        if (cls == char.class || cls == Character.class) return ((String) value).charAt(0);
+        if (cls == String.class) return value;
+        if (cls == ListNode.class) {
+            ListNode head = null;
+            List<?> items = (List<?>) value;
+            for (int i = items.size() - 1; i >= 0; i--) head = new ListNode(((Number) items.get(i)).intValue(), head);
+            return head;
+        }
+        if (cls == TreeNode.class) {
+            List<?> items = (List<?>) value;
+            if (items.isEmpty() || items.get(0) == null) return null;
+            TreeNode root = new TreeNode(((Number) items.get(0)).intValue());
+            Deque<TreeNode> queue = new ArrayDeque<>();
+            queue.add(root);
+            for (int i = 1; !queue.isEmpty() && i < items.size(); i += 2) {
+                TreeNode node = queue.poll();
+                if (items.get(i) != null) {
+                    node.left = new TreeNode(((Number) items.get(i)).intValue());
+                    queue.add(node.left);
+                }
+                if (i + 1 < items.size() && items.get(i + 1) != null) {
+                    node.right = new TreeNode(((Number) items.get(i + 1)).intValue());
+                    queue.add(node.right);
+                }
+            }
+            return root;
+        }
+        throw new IllegalArgumentException("unsupported parameter type: " + type);
+    }
+
+    static String encode(Object value) {
+        if (value == null) return "null";
+        if (value instanceof ListNode) {
+            List<Integer> values = new ArrayList<>();
+            for (ListNode node = (ListNode) value; node != null; node = node.next) values.add(node.val);
+            return encode(values);
+        }
+        if (value instanceof TreeNode) {
+            List<Integer> values = new ArrayList<>();
+            Deque<TreeNode> queue = new LinkedList<>();
+            queue.add((TreeNode) value);
+            while (!queue.isEmpty()) {
+                TreeNode node = queue.poll();
+                values.add(node == null ? null : node.val);
+                if (node != null) {
+                    queue.add(node.left);
+                    queue.add(node.right);
+                }
+            }
+            while (!values.isEmpty() && values.get(values.size() - 1) == null) values.remove(values.size() - 1);
+            return encode(values);
+        }
+        if (value instanceof Double || value instanceof Float) {
+            return String.format(Locale.ROOT, "%.5f", ((Number) value).doubleValue());
+        }
+        if (value instanceof String || value instanceof Character) {
+            String s = String.valueOf(value);
+            return "\"" + s.replace("\\", "\\\\").replace("\"", "\\\"") + "\"";
+        }
+        StringJoiner joiner = new StringJoiner(",", "[", "]");
+        if (value.getClass().isArray()) {
+            for (int i = 0; i < Array.getLength(value); i++) joiner.add(encode(Array.get(value, i)));
+            return joiner.toString();
+        }
+        if (value instanceof Collection) {
+            for (Object item : (Collection<?>) value) joiner.add(encode(item));
+            return joiner.toString();
+        }
+        return String.valueOf(value);
+    }
+
+    static class Parser {
+        private final String s;
+        private int pos = 0;
+
+        Parser(String s) {
+            this.s = s;
+        }
+
+        private void skip() {
+            while (pos < s.length() && Character.isWhitespace(s.charAt(pos))) pos++;
+        }
+
+        Object parse() {
+            skip();
+            char c = s.charAt(pos);
+            if (c == '[') {
+                List<Object> items = new ArrayList<>();
+                pos++;
+                skip();
+                if (s.charAt(pos) == ']') {
+                    pos++;
+                    return items;
+                }
+                while (true) {
+                    items.add(parse());
+                    skip();
+                    if (s.charAt(pos++) != ',') return items;
+                }
+            } else if (c == '"') {
+                StringBuilder text = new StringBuilder();
+                pos++;
+                while (s.charAt(pos) != '"') {
+                    if (s.charAt(pos) == '\\') {
+                        pos++;
+                        char e = s.charAt(pos);
+                        text.append(e == 'n' ? '\n' : e == 't' ? '\t' : e);
+                    } else {
+                        text.append(s.charAt(pos));
+                    }
+                    pos++;
+                }
+                pos++;
+                return text.toString();
+            } else if (s.startsWith("true", pos)) {
+                pos += 4;
+                return true;
+            } else if (s.startsWith("false", pos)) {
+                pos += 5;
+                return false;
+            } else if (s.startsWith("null", pos)) {
+                pos += 4;
+                return null;
+            } else {
+                int start = pos;
+                while (pos < s.length() && "+-.eE0123456789".indexOf(s.charAt(pos)) != -1) pos++;
+                String number = s.substring(start, pos);
+                if (number.contains(".") || number.contains("e") || number.contains("E")) return Double.parseDouble(number);
+                return Long.parseLong(number);
+            }
+        }
+    }
+}
+
+class ListNode {
+    int val;
+    ListNode next;
+    ListNode() {}
+    ListNode(int val) { this.val = val; }
+    ListNode(int val, ListNode next) { this.val = val; this.next = next; }
+}
+
+class TreeNode {
+    int val;
+    TreeNode left;
+    TreeNode right;
+    TreeNode() {}
+    TreeNode(int val) { this.val = val; }
+    TreeNode(int val, TreeNode left, TreeNode right) {
+        this.val = val;
+        this.left = left;
+        this.right = right;
+    }
+}
+`
+
+const rustPrelude = `#[derive(PartialEq, Eq, Clone, Debug)]
+pub struct ListNode {
+    pub val: i32,
+    pub next: Option<Box<ListNode>>,
+}
+
+impl ListNode {
+    #[inline]
+    #[allow(dead_code)]
+    fn new(val: i32) -> Self {
+        ListNode { next: None, val }
+    }
+}
+
+#[derive(Debug, PartialEq, Eq)]
+pub struct TreeNode {
+    pub val: i32,
+    pub left: Option<std::rc::Rc<std::cell::RefCell<TreeNode>>>,
+    pub right: Option<std::rc::Rc<std::cell::RefCell<TreeNode>>>,
+}
+
+impl TreeNode {
+    #[inline]
+    #[allow(dead_code)]
+    pub fn new(val: i32) -> Self {
+        TreeNode { val, left: None, right: None }
+    }
+}
+
+struct Solution;
+`
+
+const rustRuntime = `#[allow(dead_code)]
+mod tinycode {
+    use super::{ListNode, TreeNode};
+    use std::cell::RefCell;
+    use std::collections::VecDeque;
+    use std::io::Read;
+    use std::rc::Rc;
+
+    pub enum Json {
+        Null,
+        Bool(bool),
+        Number(String),
+        Str(String),
+        Array(Vec<Json>),
+    }
+
+    pub fn read_lines() -> Vec<String> {
+        let mut input = String::new();
+        std::io::stdin().read_to_string(&mut input).unwrap();
+        input.lines().filter(|line| !line.trim().is_empty()).map(String::from).collect()
+    }
+
+    pub fn parse(s: &str) -> Json {
+        let chars: Vec<char> = s.chars().collect();
+        let mut pos = 0;
+        parse_value(&chars, &mut pos)
+    }
+
+    fn skip(chars: &[char], pos: &mut usize) {
+        while *pos < chars.len() && chars[*pos].is_whitespace() {
+            *pos += 1;
+        }
+    }
+
+    fn parse_value(chars: &[char], pos: &mut usize) -> Json {
+        skip(chars, pos);
+        if *pos >= chars.len() {
+            return Json::Null;
+        }
+        match chars[*pos] {
+            '[' => {
+                *pos += 1;
+                let mut items = Vec::new();
+                skip(chars, pos);
+                if *pos < chars.len() && chars[*pos] == ']' {
+                    *pos += 1;
+                    return Json::Array(items);
+                }
+                while *pos < chars.len() {
+                    items.push(parse_value(chars, pos));
+                    skip(chars, pos);
+                    let c = chars[*pos];
+                    *pos += 1;
+                    if c != ',' {
+                        break;
+                    }
+                }
+                Json::Array(items)
+            }
+            '"' => {
+                *pos += 1;
+                let mut text = String::new();
+                while chars[*pos] != '"' {
+                    if chars[*pos] == '\\' {
+                        *pos += 1;
+                        text.push(match chars[*pos] {
+                            'n' => '\n',
+                            't' => '\t',
+                            c => c,
+                        });
+                    } else {
+                        text.push(chars[*pos]);
+                    }
+                    *pos += 1;
+                }
+                *pos += 1;
+                Json::Str(text)
+            }
+            't' => {
+                *pos += 4;
+                Json::Bool(true)
+            }
+            'f' => {
+                *pos += 5;
+                Json::Bool(false)
+            }
+            'n' => {
+                *pos += 4;
+                Json::Null
+            }
+            _ => {
+                let mut text = String::new();
+                while *pos < chars.len() && (chars[*pos].is_ascii_digit() || "+-.eE".contains(chars[*pos])) {
+                    text.push(chars[*pos]);
+                    *pos += 1;
+                }
+                Json::Number(text)
+            }
+        }
+    }
+
+    pub trait Decode: Sized {
+        fn decode(value: &Json) -> Self;
+    }
+
+    impl Decode for i32 {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Number(n) => n.parse().unwrap(),
+                _ => panic!("expected a number"),
+            }
+        }
+    }
+
+    impl Decode for i64 {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Number(n) => n.parse().unwrap(),
+                _ => panic!("expected a number"),
+            }
+        }
+    }
+
+    impl Decode for f64 {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Number(n) => n.parse().unwrap(),
+                _ => panic!("expected a number"),
+            }
+        }
+    }
+
+    impl Decode for bool {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Bool(b) => *b,
+                _ => panic!("expected a boolean"),
+            }
+        }
+    }
+
+    impl Decode for String {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Str(s) => s.clone(),
+                _ => panic!("expected a string"),
+            }
+        }
+    }
+
+    impl Decode for char {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Str(s) => s.chars().next().unwrap_or('\0'),
+                _ => panic!("expected a character"),
+            }
+        }
+    }
+
+    impl Decode for Option<i32> {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Null => None,
+                _ => Some(i32::decode(value)),
+            }
+        }
+    }
+
+    impl<T: Decode> Decode for Vec<T> {
+        fn decode(value: &Json) -> Self {
+            match value {
+                Json::Array(items) => items.iter().map(T::decode).collect(),
+                _ => panic!("expected an array"),
+            }
+        }
+    }
+
+    impl Decode for Option<Box<ListNode>> {
+        fn decode(value: &Json) -> Self {
+            let values: Vec<i32> = Decode::decode(value);
+            let mut head = None;
+            for val in values.into_iter().rev() {
+                head = Some(Box::new(ListNode { val, next: head }));
+            }
+            head
+        }
+    }
+
+    impl Decode for Option<Rc<RefCell<TreeNode>>> {
+        fn decode(value: &Json) -> Self {
+            let values: Vec<Option<i32>> = Decode::decode(value);
+            let new_node = |val: i32| Rc::new(RefCell::new(TreeNode::new(val)));
+            let root = match values.first() {
+                Some(Some(val)) => new_node(*val),
+                _ => return None,
+            };
+            let mut queue = VecDeque::new();
+            queue.push_back(root.clone());
+            let mut i = 1;
+            while i < values.len() {
+                let node = match queue.pop_front() {
+                    Some(node) => node,
+                    None => break,
+                };
+                if let Some(val) = values[i] {
+                    let left = new_node(val);
+                    node.borrow_mut().left = Some(left.clone());
+                    queue.push_back(left);
+                }
+                if let Some(Some(val)) = values.get(i + 1) {
+                    let right = new_node(*val);
+                    node.borrow_mut().right = Some(right.clone());
+                    queue.push_back(right);
+                }
+                i += 2;
+            }
+            Some(root)
+        }
+    }
+
+    pub trait Encode {
+        fn encode(&self) -> String;
+    }
+
+    impl Encode for i32 {
+        fn encode(&self) -> String {
+            self.to_string()
+        }
+    }
+
+    impl Encode for i64 {
+        fn encode(&self) -> String {
+            self.to_string()
+        }
+    }
+
+    impl Encode for f64 {
+        fn encode(&self) -> String {
+            format!("{:.5}", self)
+        }
+    }
+
+    impl Encode for bool {
+        fn encode(&self) -> String {
+            self.to_string()
+        }
+    }
+
+    impl Encode for String {
+        fn encode(&self) -> String {
+            format!("\"{}\"", self.replace('\\', "\\\\").replace('"', "\\\""))
+        }
+    }
+
+    impl Encode for char {
+        fn encode(&self) -> String {
+            self.to_string().encode()
+        }
+    }
+
+    impl<T: Encode> Encode for Vec<T> {
+        fn encode(&self) -> String {
+            let items: Vec<String> = self.iter().map(|item| item.encode()).collect();
+            format!("[{}]", items.join(","))
+        }
+    }
+
+    impl Encode for Option<Box<ListNode>> {
+        fn encode(&self) -> String {
+            let mut values = Vec::new();
+            let mut node = self;
+            while let Some(inner) = node {
+                values.push(inner.val);
+                node = &inner.next;
+            }
+            values.encode()
+        }
+    }
+
+    impl Encode for Option<Rc<RefCell<TreeNode>>> {
+        fn encode(&self) -> String {
+            let mut values = Vec::new();
+            let mut queue = VecDeque::new();
+            queue.push_back(self.clone());
+            while let Some(node) = queue.pop_front() {
+                match node {
+                    Some(node) => {
+                        let node = node.borrow();
+                        values.push(node.val.to_string());
+                        queue.push_back(node.left.clone());
+                        queue.push_back(node.right.clone());
+                    }
+                    None => values.push(String::from("null")),
+                }
+            }
+            while values.last().map(|v| v == "null").unwrap_or(false) {
+                values.pop();
+            }
+            format!("[{}]", values.join(","))
+        }
+    }
+}
+`
@@ -0,0 +1,216 @@
+package leetcode
+
+import (
+	"fmt"
+	"net/http/cookiejar"
+	"strings"
+)
+
+// Site is one of the LeetCode websites, which share most of their API but
+// differ in their GraphQL schemas for lists of problems and random picks
+type Site struct {
+	Name          string // of the provider
+	GraphqlPath   string
+	SubmitPath    string // formatted with the slug of the problem
+	CheckPath     string // formatted with the id of the submission
+	CsrfCookie    string
+	SessionCookie string
+	China         bool
+}
+
+var Global = Site{
+	Name:          "leetcode",
+	GraphqlPath:   "/graphql",
+	SubmitPath:    "/problems/%s/submit/",
+	CheckPath:     "/submissions/detail/%d/check/",
+	CsrfCookie:    "csrftoken",
+	SessionCookie: "LEETCODE_SESSION",
+}
+
+var China = Site{
+	Name:          "leetcode-cn",
+	GraphqlPath:   "/graphql/",
+	SubmitPath:    "/problems/%s/submit/",
+	CheckPath:     "/submissions/detail/%d/check/",
+	CsrfCookie:    "csrftoken",
+	SessionCookie: "LEETCODE_SESSION",
+	China:         true,
+}
+
+func (client *Client) Site() Site {
+	return client.site
+}
+
+// translated is true if prompts should be in Chinese rather than in English
+func (client *Client) translated() bool {
+	return client.site.China && client.locale != "en"
+}
+
+// FetchCsrfToken gets a fresh CSRF token, which leetcode.cn hands out to anyone
+// visiting it, so that only the session token needs to be taken from a
+// browser to log in
+func (client *Client) FetchCsrfToken() (string, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return "", err
+	}
+	client.transport.SetCookieJar(jar)
+
+	base, err := client.transport.ResolveReference("/")
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.transport.RawGet(base.String())
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	for _, cookie := range jar.Cookies(base) {
+		if cookie.Name == client.site.CsrfCookie {
+			return cookie.Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s did not set a %s cookie", base, client.site.CsrfCookie)
+}
+
+// chinaQuestionSummary is a problem in a list of leetcode.cn, which lacks the
+// internal id of problems
+type chinaQuestionSummary struct {
+	FrontendQuestionId string     `json:"frontendQuestionId"`
+	Title              string     `json:"title"`
+	TitleCn            string     `json:"titleCn"`
+	TitleSlug          string     `json:"titleSlug"`
+	Difficulty         string     `json:"difficulty"` // e.g. EASY
+	Status             string     `json:"status"`     // "AC", "TRIED" or empty
+	PaidOnly           bool       `json:"paidOnly"`
+	TopicTags          []TopicTag `json:"topicTags"`
+}
+
+// titleCase turns difficulties such as EASY into Easy, as on leetcode.com
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+}
+
+func (question *chinaQuestionSummary) summary(translated bool) QuestionSummary {
+	output := QuestionSummary{
+		QuestionFrontendId: question.FrontendQuestionId,
+		Title:              question.Title,
+		TitleSlug:          question.TitleSlug,
+		Difficulty:         titleCase(question.Difficulty),
+		IsPaidOnly:         question.PaidOnly,
+		TopicTags:          question.TopicTags,
+	}
+
+	if translated && question.TitleCn != "" {
+		output.Title = question.TitleCn
+	}
+
+	switch question.Status {
+	case "AC":
+		output.Status = "ac"
+	case "TRIED":
+		output.Status = "notac"
+	}
+
+	return output
+}
+
+func (client *Client) getChinaQuestionList(filters Filters, categorySlug string, skip uint64, limit uint64) ([]QuestionSummary, error) {
+	query := `
+query problemsetQuestionList($categorySlug: String, $limit: Int, $skip: Int, $filters: QuestionListFilterInput) {
+  problemsetQuestionList(categorySlug: $categorySlug, limit: $limit, skip: $skip, filters: $filters) {
+    total
+    questions {
+      frontendQuestionId
+      title
+      titleCn
+      titleSlug
+      difficulty
+      status
+      paidOnly
+      topicTags {
+        name
+        slug
+      }
+    }
+  }
+}`
+
+	type Variables struct {
+		CategorySlug string  `json:"categorySlug"`
+		Skip         uint64  `json:"skip"`
+		Limit        uint64  `json:"limit"`
+		Filters      Filters `json:"filters"`
+	}
+
+	variables := Variables{
+		categorySlug,
+		skip,
+		limit,
+		filters,
+	}
+
+	type QuestionList struct {
+		Total     uint64                 `json:"total"`
+		Questions []chinaQuestionSummary `json:"questions"`
+	}
+
+	type QueryData struct {
+		QuestionList QuestionList `json:"problemsetQuestionList"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("problemsetQuestionList", query, variables, &output); err != nil {
+		return nil, err
+	}
+
+	var questions []QuestionSummary
+	for _, question := range output.Data.QuestionList.Questions {
+		questions = append(questions, question.summary(client.translated()))
+	}
+	return questions, nil
+}
+
+func (client *Client) getChinaRandomQuestionSlug(filters Filters, categorySlug string) (string, error) {
+	query := `
+query problemsetRandomFilteredQuestion($categorySlug: String!, $filters: QuestionListFilterInput) {
+  problemsetRandomFilteredQuestion(categorySlug: $categorySlug, filters: $filters)
+}`
+
+	type Variables struct {
+		CategorySlug string  `json:"categorySlug"`
+		Filters      Filters `json:"filters"`
+	}
+
+	type QueryData struct {
+		TitleSlug string `json:"problemsetRandomFilteredQuestion"`
+	}
+
+	type QueryResult struct {
+		Data QueryData `json:"data"`
+	}
+
+	output := QueryResult{}
+	if err := client.transport.DoQuery("problemsetRandomFilteredQuestion", query, Variables{categorySlug, filters}, &output); err != nil {
+		return "", err
+	}
+
+	if output.Data.TitleSlug == "" {
+		return "", fmt.Errorf("could not find a viable question, try removing conditions")
+	}
+	return output.Data.TitleSlug, nil
+}
+
+func (client *Client) unsupported(feature string) error {
+	return fmt.Errorf("%s are not supported on %s yet", feature, client.site.Name)
+}
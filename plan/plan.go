@@ -0,0 +1,128 @@
+// Package plan reads and writes lists of problems to work through, such as
+// study plans, kept as TOML or YAML files
+package plan
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Entry struct {
+	Provider string `mapstructure:"provider"` // default is the one of the list
+	Slug     string `mapstructure:"slug"`
+	Id       string `mapstructure:"id"`
+	Contest  string `mapstructure:"contest"`
+	Title    string `mapstructure:"title"`
+	Lang     string `mapstructure:"lang"` // default is the one of the list
+	Notes    string `mapstructure:"notes"`
+}
+
+type List struct {
+	Name     string  `mapstructure:"name"`
+	Provider string  `mapstructure:"provider"`
+	Lang     string  `mapstructure:"lang"`
+	Problems []Entry `mapstructure:"problems"`
+}
+
+// Load reads the list at path, as TOML or YAML depending on its extension.
+// Entries inherit the provider and language of the list if they have none.
+func Load(path string) (*List, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		v.SetConfigType("yaml")
+	default:
+		v.SetConfigType("toml")
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	list := List{}
+	if err := v.Unmarshal(&list); err != nil {
+		return nil, err
+	}
+
+	if list.Name == "" {
+		list.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	for i := range list.Problems {
+		entry := &list.Problems[i]
+		if entry.Slug == "" {
+			return nil, fmt.Errorf("%s: problem %d has no slug", path, i+1)
+		}
+		if entry.Provider == "" {
+			entry.Provider = list.Provider
+		}
+		if entry.Provider == "" {
+			return nil, fmt.Errorf("%s: problem %s has no provider", path, entry.Slug)
+		}
+		if entry.Lang == "" {
+			entry.Lang = list.Lang
+		}
+	}
+
+	return &list, nil
+}
+
+// quote writes s as a TOML basic string
+func quote(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString("\\n")
+		case r == '\t':
+			buf.WriteString("\\t")
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// Write writes the list as TOML, leaving out what entries inherit from it
+func (list *List) Write(writer io.Writer) error {
+	var buf strings.Builder
+
+	field := func(key string, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s = %s\n", key, quote(value))
+		}
+	}
+
+	field("name", list.Name)
+	field("provider", list.Provider)
+	field("lang", list.Lang)
+
+	for _, entry := range list.Problems {
+		buf.WriteString("\n[[problems]]\n")
+		if entry.Provider != list.Provider {
+			field("provider", entry.Provider)
+		}
+		field("slug", entry.Slug)
+		field("id", entry.Id)
+		field("contest", entry.Contest)
+		field("title", entry.Title)
+		if entry.Lang != list.Lang {
+			field("lang", entry.Lang)
+		}
+		field("notes", entry.Notes)
+	}
+
+	_, err := io.WriteString(writer, buf.String())
+	return err
+}
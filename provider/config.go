@@ -8,4 +8,5 @@ type BackendConfig struct {
 	Csrf       string `mapstructure:"csrf"`
 	CsrfHeader string `mapstructure:"csrf-header"`
 	Session    string `mapstructure:"session"`
+	Locale     string `mapstructure:"locale"` // of prompts, on providers which translate them
 }
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxColumn is the width past which lines are cut in side by side diffs
+const maxColumn = 40
+
+func column(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n > width {
+		return string([]rune(s)[:width-1]) + "…"
+	} else {
+		return s + strings.Repeat(" ", width-n)
+	}
+}
+
+// SideBySide lays out the expected and actual outputs next to each other, line
+// by line, marking those which differ as diff -y does: | if both sides
+// differ, < or > if one of them has no more lines
+func SideBySide(expected string, got string) string {
+	left := strings.Split(strings.TrimRight(expected, "\n"), "\n")
+	right := strings.Split(strings.TrimRight(got, "\n"), "\n")
+
+	width := len("expected")
+	for _, line := range left {
+		if n := utf8.RuneCountInString(line); n > width {
+			width = n
+		}
+	}
+	if width > maxColumn {
+		width = maxColumn
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s   got\n", column("expected", width))
+
+	for i := 0; i < len(left) || i < len(right); i++ {
+		switch {
+		case i >= len(left):
+			fmt.Fprintf(&buf, "%s > %s\n", column("", width), right[i])
+		case i >= len(right):
+			fmt.Fprintf(&buf, "%s <\n", column(left[i], width))
+		case left[i] != right[i]:
+			fmt.Fprintf(&buf, "%s | %s\n", column(left[i], width), right[i])
+		default:
+			fmt.Fprintf(&buf, "%s   %s\n", column(left[i], width), right[i])
+		}
+	}
+
+	return buf.String()
+}
+
+// IsMultiline is true if either output spans more than one line, in which case
+// it is easier to read them side by side
+func IsMultiline(expected string, got string) bool {
+	return strings.Contains(strings.TrimRight(expected, "\n"), "\n") || strings.Contains(strings.TrimRight(got, "\n"), "\n")
+}
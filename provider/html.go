@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var htmlTag = regexp.MustCompile("<\\/?[^>]*>")
+
+// HtmlToText strips the tags of s and decodes its entities, leaving its
+// whitespace as is
+func HtmlToText(s string) string {
+	output := htmlTag.ReplaceAllString(s, "")
+
+	// non-breaking spaces are kept as plain spaces, for the sake of terminals
+	output = strings.ReplaceAll(output, "&nbsp;", " ")
+
+	return html.UnescapeString(output)
+}
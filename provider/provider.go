@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"regexp"
 	"strings"
@@ -36,6 +37,15 @@ func (filters *Filters) GetFilterOrDefault(name string) string {
 	return value
 }
 
+// Map returns a copy of all the filters set
+func (filters *Filters) Map() map[string]string {
+	output := map[string]string{}
+	for k, v := range filters.raw {
+		output[k] = v
+	}
+	return output
+}
+
 func (filters *Filters) AddFilter(name string, value string) error {
 	if validateFilterElement(name) && validateFilterElement(value) {
 		if filters.raw == nil {
@@ -81,6 +91,7 @@ type Provider interface {
 	GetChallenge(Filters) (Challenge, error)
 	FindNextChallenge(Filters) (Filters, error)
 	Submit(Filters, Lang, string) (SubmissionReport, error)
+	List(filters Filters, offset uint64, limit uint64) ([]ChallengeSummary, error)
 }
 
 type Challenge interface {
@@ -88,15 +99,178 @@ type Challenge interface {
 	Prompt() string
 	Files() (map[string]string, error)
 	Identify() Filters
+	Details() ChallengeDetails
+}
+
+// Driven is implemented by challenges whose snippets do not run on their own,
+// and which can provide driver code to put before and after the submit region
+type Driven interface {
+	Driver(Lang) (string, string, error)
+}
+
+// Annotated is implemented by challenges which know more of themselves than
+// their prompt, e.g. hints or similar problems
+type Annotated interface {
+	Annotations() Annotations
+}
+
+type SimilarChallenge struct {
+	Title      string
+	Slug       string
+	Difficulty string
+}
+
+// Annotations go in the header of checked out challenges, except for hints
+// which are only counted there so as not to give anything away
+type Annotations struct {
+	Tags           []string
+	AcceptanceRate string // e.g. 52.3%
+	Accepted       string // submissions, e.g. 5.2M
+	Submissions    string
+	Companies      []string
+	Similar        []SimilarChallenge
+	Hints          []string
+}
+
+func (annotations *Annotations) IsEmpty() bool {
+	return len(annotations.Tags) == 0 && annotations.AcceptanceRate == "" && len(annotations.Companies) == 0 &&
+		len(annotations.Similar) == 0 && len(annotations.Hints) == 0
+}
+
+func (annotations *Annotations) Render(buf io.StringWriter) {
+	if tags := annotations.Tags; len(tags) != 0 {
+		buf.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(tags, ", ")))
+	}
+
+	if rate := annotations.AcceptanceRate; rate != "" {
+		buf.WriteString(fmt.Sprintf("Acceptance rate: %s", rate))
+		if annotations.Accepted != "" && annotations.Submissions != "" {
+			buf.WriteString(fmt.Sprintf(" (%s accepted out of %s submissions)", annotations.Accepted, annotations.Submissions))
+		}
+		buf.WriteString("\n")
+	}
+
+	if companies := annotations.Companies; len(companies) != 0 {
+		buf.WriteString(fmt.Sprintf("Companies: %s\n", strings.Join(companies, ", ")))
+	}
+
+	if similar := annotations.Similar; len(similar) != 0 {
+		buf.WriteString("Similar questions:\n")
+		for _, challenge := range similar {
+			buf.WriteString(fmt.Sprintf("  - %s (%s, %s)\n", challenge.Title, challenge.Slug, challenge.Difficulty))
+		}
+	}
+
+	if count := len(annotations.Hints); count == 1 {
+		buf.WriteString("Hints: 1, reveal it with `tinycode hint PATH`\n")
+	} else if count > 1 {
+		buf.WriteString(fmt.Sprintf("Hints: %d, reveal them one at a time with `tinycode hint PATH N`\n", count))
+	}
+}
+
+type ChallengeDetails struct {
+	Title      string
+	Difficulty string
+	Tags       []string
+	TagSlugs   map[string]string // by name, on providers which filter by tag
+}
+
+// ChallengeSummary is what is known of a challenge when listing them
+type ChallengeSummary struct {
+	Filters Filters
+	Details ChallengeDetails
+	Status  string // solved, attempted or todo, empty if unknown
+}
+
+const (
+	Solved    = "solved"
+	Attempted = "attempted"
+	Todo      = "todo"
+)
+
+// Profile is what a provider knows of the progress of the signed in user
+type Profile struct {
+	Username    string            `json:"username"`
+	Solved      map[string]uint64 `json:"solved,omitempty"`    // by difficulty
+	Attempted   map[string]uint64 `json:"attempted,omitempty"` // by difficulty, not solved yet
+	Untouched   map[string]uint64 `json:"untouched,omitempty"` // by difficulty
+	Tags        map[string]uint64 `json:"tags,omitempty"`      // solved, by tag
+	TagSlugs    map[string]string `json:"tag_slugs,omitempty"` // by name
+	Langs       map[string]uint64 `json:"langs,omitempty"`     // solved, by language
+	Submissions uint64            `json:"submissions,omitempty"`
+	Accepted    uint64            `json:"accepted,omitempty"` // submissions
+	Badges      []Badge           `json:"badges,omitempty"`
+	Scores      []Score           `json:"scores,omitempty"`
+}
+
+type Badge struct {
+	Name   string `json:"name"`
+	Stars  int64  `json:"stars"`
+	Solved int64  `json:"solved"`
+	Total  int64  `json:"total"`
+}
+
+type Score struct {
+	Track string  `json:"track"`
+	Score float64 `json:"score"`
+	Rank  int64   `json:"rank,omitempty"`
+}
+
+// Profiled is implemented by providers which can report on the progress of the
+// signed in user
+type Profiled interface {
+	Profile() (*Profile, error)
+}
+
+// ProblemList is a list of problems kept on a provider, such as a list of
+// favorites or a study plan
+type ProblemList struct {
+	Slug     string
+	Name     string
+	Problems []ChallengeSummary
+}
+
+// Curated is implemented by providers which keep lists of problems
+type Curated interface {
+	Favorites() ([]ProblemList, error)
+	StudyPlan(slug string) (*ProblemList, error)
+}
+
+// Progress is the state of a submission which is still being judged
+type Progress struct {
+	Submission string `json:"submission"`
+	State      string `json:"state"`
+	Elapsed    int64  `json:"elapsed_ms"`
+}
+
+// Observable is implemented by providers which can report on the progress of
+// submissions while they are being judged
+type Observable interface {
+	OnProgress(func(Progress))
 }
 
 type SubmissionReport interface {
 	HasSucceeded() bool
 	Identify() string
+	Class() VerdictClass
 	Statistics() SubmissionStatistics
 	ErrorReport() *ErrorReport
 }
 
+// VerdictClass sorts the verdicts of all providers into broad categories
+type VerdictClass string
+
+const (
+	Accepted            VerdictClass = "accepted"
+	WrongAnswer                      = "wrong_answer"
+	CompileError                     = "compile_error"
+	RuntimeError                     = "runtime_error"
+	TimeLimitExceeded                = "time_limit_exceeded"
+	MemoryLimitExceeded              = "memory_limit_exceeded"
+	OutputLimitExceeded              = "output_limit_exceeded"
+	OtherError                       = "other"
+)
+
 type SubmissionStatistics struct {
 	TotalTestCases    uint64
 	Runtime           string
@@ -168,6 +342,12 @@ const (
 	Erlang            = "erlang"
 	Elixir            = "elixir"
 	Bash              = "bash"
+	MySQL             = "mysql"
+	MsSQL             = "mssql"
+	PostgreSQL        = "postgresql"
+	OracleSQL         = "oraclesql"
+	DB2               = "db2"
+	PythonData        = "pythondata" // pandas
 )
 
 func ParseLang(s string) (*Lang, error) {
@@ -199,7 +379,13 @@ func ParseLang(s string) (*Lang, error) {
 		Racket,
 		Erlang,
 		Elixir,
-		Bash:
+		Bash,
+		MySQL,
+		MsSQL,
+		PostgreSQL,
+		OracleSQL,
+		DB2,
+		PythonData:
 		return &Lang{raw: s}, nil
 	default:
 		return nil, fmt.Errorf("unknown or unsupported lang: %s", s)
@@ -214,12 +400,24 @@ func (lang *Lang) Is(s string) bool {
 	return lang.String() == s
 }
 
+// IsSQL is true for the dialects of SQL
+func (lang *Lang) IsSQL() bool {
+	switch lang.raw {
+	case MySQL, MsSQL, PostgreSQL, OracleSQL, DB2:
+		return true
+	default:
+		return false
+	}
+}
+
 func (lang *Lang) Comment() (string, string, string, string) {
 	switch lang.raw {
 	case C, ObjectiveC, Cpp, Cpp14, Java, Java8, Java15, Csharp, JavaScript, Swift, Golang, Scala, Kotlin, Php, TypeScript:
 		return "/*", "*/", " * ", "// "
-	case Python, Python3, Pypy, Pypy3:
+	case Python, Python3, Pypy, Pypy3, PythonData:
 		return "\"\"\"", "\"\"\"", "   ", "# "
+	case MySQL, MsSQL, PostgreSQL, OracleSQL, DB2:
+		return "/*", "*/", " * ", "-- "
 	case Ruby:
 		return "=begin", "=end", "", "# "
 	case Rust:
@@ -297,6 +495,18 @@ func (lang *Lang) Pretty() string {
 		return "ObjectiveC"
 	case Bash:
 		return "Bash"
+	case MySQL:
+		return "MySQL"
+	case MsSQL:
+		return "MS SQL Server"
+	case PostgreSQL:
+		return "PostgreSQL"
+	case OracleSQL:
+		return "Oracle"
+	case DB2:
+		return "DB2"
+	case PythonData:
+		return "Pandas"
 	default:
 		panic(fmt.Sprintf("unknown lang variant: %s", lang.raw))
 	}
@@ -349,6 +559,8 @@ func ParseExt(ext string) (*Lang, error) {
 		raw = ObjectiveC
 	case "sh":
 		raw = Bash
+	case "sql":
+		raw = MySQL
 	default:
 		return nil, fmt.Errorf("don't know what language associates to extension: %s", ext)
 	}
@@ -377,12 +589,14 @@ func (lang *Lang) Ext() string {
 		return "hs"
 	case Clojure:
 		return "clj"
-	case Python, Python3, Pypy, Pypy3:
+	case Python, Python3, Pypy, Pypy3, PythonData:
 		return "py"
 	case ObjectiveC:
 		return "m"
 	case Bash:
 		return "sh"
+	case MySQL, MsSQL, PostgreSQL, OracleSQL, DB2:
+		return "sql"
 	default:
 		panic(fmt.Sprintf("don't know what extension to associate to: %s", lang.raw))
 	}
@@ -396,6 +610,12 @@ func EncodeChallenge(backend string, lang Lang, filters Filters, challenge Chall
 		return err
 	}
 	headerBuf.WriteString("\n\n")
+	if annotated, ok := challenge.(Annotated); ok {
+		if annotations := annotated.Annotations(); !annotations.IsEmpty() {
+			annotations.Render(&headerBuf)
+			headerBuf.WriteString("\n")
+		}
+	}
 	headerBuf.WriteString(challenge.Prompt())
 	header := headerBuf.String()
 
@@ -424,6 +644,18 @@ func EncodeChallenge(backend string, lang Lang, filters Filters, challenge Chall
 
 	writer.WriteString(suffix)
 
+	var prelude, epilogue string
+	if driven, ok := challenge.(Driven); ok {
+		var err error
+		if prelude, epilogue, err = driven.Driver(lang); err != nil {
+			log.Printf("not adding driver code: %s", err)
+		}
+	}
+
+	if prelude != "" {
+		writer.WriteString(fmt.Sprintf("\n%s", prelude))
+	}
+
 	if lang.raw == Rust {
 		// Switch to content comments from now on
 		single = "// "
@@ -438,11 +670,18 @@ func EncodeChallenge(backend string, lang Lang, filters Filters, challenge Chall
 	}
 	writer.WriteString(fmt.Sprintf("\n%s%s submit region end\n\n", single, backend))
 
+	if epilogue != "" {
+		writer.WriteString(epilogue)
+	}
+
 	return nil
 }
 
-func DecodeSolution(backend string, reader io.Reader) (*string, error) {
+// DecodeSolution extracts the code of the submission region of the source read
+// from reader, along with where its lines are in the source
+func DecodeSolution(backend string, reader io.Reader) (*string, SourceMap, error) {
 	buf := bytes.Buffer{}
+	var sourceMap SourceMap
 
 	regionBegin := fmt.Sprintf("%s submit region begin", backend)
 	reBegin := regexp.MustCompile(regionBegin)
@@ -458,8 +697,10 @@ func DecodeSolution(backend string, reader io.Reader) (*string, error) {
 	)
 
 	mode := Otherwise
+	lineNo := 0
 	for scanner.Scan() {
 		line := string(scanner.Bytes())
+		lineNo++
 
 		if reBegin.MatchString(line) {
 			mode = SubmissionCode
@@ -470,13 +711,14 @@ func DecodeSolution(backend string, reader io.Reader) (*string, error) {
 
 		if mode == SubmissionCode {
 			buf.WriteString(fmt.Sprintln(line))
+			sourceMap = append(sourceMap, lineNo)
 		}
 	}
 
 	if mode != SubmissionCode {
-		return nil, fmt.Errorf("provided source does not have a submission region")
+		return nil, nil, fmt.Errorf("provided source does not have a submission region")
 	}
 
 	output := buf.String()
-	return &output, nil
+	return &output, sourceMap, nil
 }
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceMap maps the lines of a decoded solution to those of its source: line
+// n of the solution is line SourceMap[n-1] of the source
+type SourceMap []int
+
+// Line maps a line of the solution, if it is one, e.g. not a line of driver
+// code the judge added
+func (sourceMap SourceMap) Line(line int) (int, bool) {
+	if line < 1 || line > len(sourceMap) {
+		return line, false
+	}
+	return sourceMap[line-1], true
+}
+
+// lineRef matches the ways judges refer to lines in their messages, i.e.
+// "Line 5: Char 12" (leetcode), "line 5" (python tracebacks) and
+// "solution.cc:5:12" or "Solution.java:5" (compilers)
+var lineRef = regexp.MustCompile(`\b(?:Line|line) (\d+)(?:(?:: Char |, column )(\d+))?|[\w./-]+\.\w+:(\d+)(?::(\d+))?`)
+
+// Diagnostic is an error at a position of the source of a solution, which
+// formats as compilers do so that editors can jump to it
+type Diagnostic struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	if diagnostic.Column != 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", diagnostic.Path, diagnostic.Line, diagnostic.Column, diagnostic.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", diagnostic.Path, diagnostic.Line, diagnostic.Message)
+}
+
+// relocate rewrites the line references of msg, returning the diagnostics
+// found along the way. Those with no message of their own get fallback.
+func (sourceMap SourceMap) relocate(msg string, path string, fallback string) (string, []Diagnostic) {
+	var diagnostics []Diagnostic
+	var lines []string
+
+	for _, line := range strings.Split(msg, "\n") {
+		var buf strings.Builder
+		last := 0
+
+		for _, match := range lineRef.FindAllStringSubmatchIndex(line, -1) {
+			lineStart, lineEnd := match[2], match[3]
+			colStart, colEnd := match[4], match[5]
+			if lineStart == -1 {
+				lineStart, lineEnd = match[6], match[7]
+				colStart, colEnd = match[8], match[9]
+			}
+
+			n, _ := strconv.Atoi(line[lineStart:lineEnd])
+			mapped, ok := sourceMap.Line(n)
+			if !ok {
+				continue
+			}
+
+			buf.WriteString(line[last:lineStart])
+			buf.WriteString(strconv.Itoa(mapped))
+			last = lineEnd
+
+			diagnostic := Diagnostic{Path: path, Line: mapped}
+			if colStart != -1 {
+				diagnostic.Column, _ = strconv.Atoi(line[colStart:colEnd])
+			}
+			diagnostic.Message = strings.Trim(line[match[1]:], " :,")
+			if diagnostic.Message == "" {
+				diagnostic.Message = fallback
+			} else if strings.HasPrefix(diagnostic.Message, "in ") {
+				// a frame of a traceback, e.g. `File "Solution.py", line 5, in f`
+				diagnostic.Message = fmt.Sprintf("%s (%s)", fallback, diagnostic.Message)
+			}
+			diagnostics = append(diagnostics, diagnostic)
+		}
+
+		buf.WriteString(line[last:])
+		lines = append(lines, buf.String())
+	}
+
+	return strings.Join(lines, "\n"), diagnostics
+}
+
+// RelocatedReport is a report whose errors refer to the lines of the source of
+// the solution rather than to those of the submitted code
+type RelocatedReport struct {
+	SubmissionReport
+	errorReport *ErrorReport
+	Diagnostics []Diagnostic
+}
+
+// Relocate maps the line numbers of the errors of report with sourceMap, path
+// being that of the source. Only compile and runtime errors are relocated, the
+// context of the others is test data rather than code.
+func Relocate(report SubmissionReport, sourceMap SourceMap, path string) *RelocatedReport {
+	errorReport := report.ErrorReport()
+	output := RelocatedReport{SubmissionReport: report, errorReport: errorReport}
+
+	switch report.Class() {
+	case CompileError, RuntimeError:
+	default:
+		return &output
+	}
+	if errorReport == nil {
+		return &output
+	}
+
+	relocated := *errorReport
+	seen := map[Diagnostic]bool{}
+	for _, field := range []*string{&relocated.ErrorMsg, &relocated.CtxHeader, &relocated.CtxMsg} {
+		var diagnostics []Diagnostic
+		*field, diagnostics = sourceMap.relocate(*field, path, errorReport.ErrorMsg)
+		for _, diagnostic := range diagnostics {
+			if !seen[diagnostic] {
+				seen[diagnostic] = true
+				output.Diagnostics = append(output.Diagnostics, diagnostic)
+			}
+		}
+	}
+
+	output.errorReport = &relocated
+	return &output
+}
+
+func (report *RelocatedReport) ErrorReport() *ErrorReport {
+	return report.errorReport
+}
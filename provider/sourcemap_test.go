@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// source has its submit region on lines 5 to 7
+const source = `// leetcode metadata: slug:two-sum
+//
+// Given an array of integers...
+
+// leetcode submit region begin
+int twoSum(int a, int b) {
+    return a + b
+}
+// leetcode submit region end
+`
+
+type fakeReport struct {
+	class       VerdictClass
+	errorReport *ErrorReport
+}
+
+func (report *fakeReport) HasSucceeded() bool               { return report.class == Accepted }
+func (report *fakeReport) Identify() string                 { return "fake" }
+func (report *fakeReport) Class() VerdictClass              { return report.class }
+func (report *fakeReport) Statistics() SubmissionStatistics { return SubmissionStatistics{} }
+func (report *fakeReport) ErrorReport() *ErrorReport        { return report.errorReport }
+
+func TestRelocate(t *testing.T) {
+	_, sourceMap, err := DecodeSolution("leetcode", strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		class       VerdictClass
+		report      ErrorReport
+		want        ErrorReport
+		diagnostics []string
+	}{
+		{
+			name:        "header offset",
+			class:       CompileError,
+			report:      ErrorReport{ErrorMsg: "Line 2: Char 13: error: expected ';' after return statement"},
+			want:        ErrorReport{ErrorMsg: "Line 7: Char 13: error: expected ';' after return statement"},
+			diagnostics: []string{"two-sum.cpp:7:13: error: expected ';' after return statement"},
+		},
+		{
+			name:   "outside of the submit region",
+			class:  RuntimeError,
+			report: ErrorReport{ErrorMsg: "AddressSanitizer: heap-buffer-overflow", CtxMsg: "Line 42: Char 3: in main"},
+			want:   ErrorReport{ErrorMsg: "AddressSanitizer: heap-buffer-overflow", CtxMsg: "Line 42: Char 3: in main"},
+		},
+		{
+			name:        "compiler output",
+			class:       CompileError,
+			report:      ErrorReport{ErrorMsg: "compile error", CtxMsg: "solution.cc:1:5: error: unknown type name 'int2'"},
+			want:        ErrorReport{ErrorMsg: "compile error", CtxMsg: "solution.cc:6:5: error: unknown type name 'int2'"},
+			diagnostics: []string{"two-sum.cpp:6:5: error: unknown type name 'int2'"},
+		},
+		{
+			name:   "wrong answer",
+			class:  WrongAnswer,
+			report: ErrorReport{ErrorMsg: "wrong answer", CtxHeader: "Input", CtxMsg: "line 3\nline 2"},
+			want:   ErrorReport{ErrorMsg: "wrong answer", CtxHeader: "Input", CtxMsg: "line 3\nline 2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errorReport := test.report
+			relocated := Relocate(&fakeReport{class: test.class, errorReport: &errorReport}, sourceMap, "two-sum.cpp")
+
+			if got := *relocated.ErrorReport(); got != test.want {
+				t.Errorf("got error report %+v, want %+v", got, test.want)
+			}
+
+			var diagnostics []string
+			for _, diagnostic := range relocated.Diagnostics {
+				diagnostics = append(diagnostics, diagnostic.String())
+			}
+			if strings.Join(diagnostics, "\n") != strings.Join(test.diagnostics, "\n") {
+				t.Errorf("got diagnostics %q, want %q", diagnostics, test.diagnostics)
+			}
+		})
+	}
+}
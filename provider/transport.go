@@ -15,6 +15,7 @@ type TransportClient struct {
 	base            url.URL
 	CsrfToken       string
 	CsrfTokenHeader string
+	GraphqlPath     string // "/graphql" if empty
 }
 
 func NewTransportClient(base url.URL) TransportClient {
@@ -75,6 +76,10 @@ func (client *TransportClient) RawDo(r *http.Request) (*http.Response, error) {
 	return client.raw.Do(r)
 }
 
+func (client *TransportClient) RawGet(url string) (*http.Response, error) {
+	return client.raw.Get(url)
+}
+
 func (client *TransportClient) Do(method string, path string, input interface{}, output interface{}) error {
 	reqUrl, err := client.ResolveReference(path)
 	if err != nil {
@@ -126,7 +131,12 @@ func (client *TransportClient) DoQuery(operationName string, query string, varia
 		variables,
 	}
 
-	err := client.Do("POST", "/graphql", req, output)
+	path := client.GraphqlPath
+	if path == "" {
+		path = "/graphql"
+	}
+
+	err := client.Do("POST", path, req, output)
 
 	return err
 }
@@ -0,0 +1,68 @@
+package provider
+
+import "math"
+
+// Verdict is the outcome of a submission in a form which can be serialized,
+// e.g. to be passed to hooks or printed as JSON
+type Verdict struct {
+	Succeeded  bool                `json:"succeeded"`
+	Class      VerdictClass        `json:"class"`
+	Submission string              `json:"submission"`
+	Statistics *VerdictStatistics  `json:"statistics,omitempty"`
+	Error      *VerdictErrorReport `json:"error,omitempty"`
+}
+
+type VerdictStatistics struct {
+	TotalTestCases    uint64   `json:"total_test_cases,omitempty"`
+	Runtime           string   `json:"runtime,omitempty"`
+	RuntimePercentile *float64 `json:"runtime_percentile,omitempty"`
+	Memory            string   `json:"memory,omitempty"`
+	MemoryPercentile  *float64 `json:"memory_percentile,omitempty"`
+	Score             string   `json:"score,omitempty"`
+	MaxScore          string   `json:"max_score,omitempty"`
+}
+
+type VerdictErrorReport struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+	Header  string `json:"header,omitempty"`
+	Context string `json:"context,omitempty"`
+}
+
+// percentile drops unknown (NaN) percentiles, which cannot be serialized
+func percentile(value float64) *float64 {
+	if math.IsNaN(value) {
+		return nil
+	}
+	return &value
+}
+
+func NewVerdict(report SubmissionReport) Verdict {
+	verdict := Verdict{
+		Succeeded:  report.HasSucceeded(),
+		Class:      report.Class(),
+		Submission: report.Identify(),
+	}
+
+	if verdict.Succeeded {
+		stats := report.Statistics()
+		verdict.Statistics = &VerdictStatistics{
+			TotalTestCases:    stats.TotalTestCases,
+			Runtime:           stats.Runtime,
+			RuntimePercentile: percentile(stats.RuntimePercentile),
+			Memory:            stats.Memory,
+			MemoryPercentile:  percentile(stats.MemoryPercentile),
+			Score:             stats.Score,
+			MaxScore:          stats.MaxScore,
+		}
+	} else if errorReport := report.ErrorReport(); errorReport != nil {
+		verdict.Error = &VerdictErrorReport{
+			Class:   errorReport.ErrorClass,
+			Message: errorReport.ErrorMsg,
+			Header:  errorReport.CtxHeader,
+			Context: errorReport.CtxMsg,
+		}
+	}
+
+	return verdict
+}
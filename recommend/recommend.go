@@ -0,0 +1,302 @@
+// Package recommend ranks problems to work on next, targeting the weak areas
+// of the user as told by the local history and the provider
+package recommend
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights of the factors of the score of a problem, which add up to 1
+const (
+	WeaknessWeight   float64 = 0.5
+	DifficultyWeight         = 0.3
+	RecencyWeight            = 0.2
+)
+
+// AttemptedBonus is added to the score of problems attempted but not solved
+const AttemptedBonus = 0.1
+
+// RecentSubmissions is how many of the last submissions set the difficulty to
+// aim for
+const RecentSubmissions = 10
+
+// StaleDays is after how many days without practice a topic is considered
+// forgotten
+const StaleDays = 30
+
+// Levels of difficulty, easiest first
+var Levels = []string{"easy", "medium", "hard", "advanced", "expert"}
+
+func level(difficulty string) int {
+	for i, l := range Levels {
+		if strings.EqualFold(l, difficulty) {
+			return i
+		}
+	}
+	return -1
+}
+
+type Factor struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"` // between 0 and 1, before weighting
+	Detail string  `json:"detail"`
+}
+
+type Candidate struct {
+	Summary provider.ChallengeSummary
+	Score   float64
+	Factors []Factor
+}
+
+type topic struct {
+	submissions uint64
+	accepted    uint64
+	last        time.Time
+}
+
+// Model is what is known of the skills of the user
+type Model struct {
+	topics       map[string]*topic
+	coverage     map[string]float64 // solved problems of each tag, relative to the most solved one
+	solved       map[string]bool    // slugs of problems solved
+	target       int                // level of difficulty to aim for
+	targetDetail string
+	now          time.Time
+}
+
+// NewModel learns the skills of the user on backend from the history and,
+// if not nil, the profile kept by the provider
+func NewModel(backend string, entries []history.Entry, profile *provider.Profile, now time.Time) *Model {
+	model := Model{
+		topics:   map[string]*topic{},
+		coverage: map[string]float64{},
+		solved:   map[string]bool{},
+		now:      now,
+	}
+
+	var recent []history.Entry
+	for _, entry := range entries {
+		if entry.Provider != backend || entry.Event != history.Submit {
+			continue
+		}
+		recent = append(recent, entry)
+
+		if entry.IsAccepted() {
+			model.solved[entry.Identity["slug"]] = true
+		}
+
+		for _, tag := range entry.Tags {
+			t, ok := model.topics[tag]
+			if !ok {
+				t = &topic{}
+				model.topics[tag] = t
+			}
+			t.submissions++
+			if entry.IsAccepted() {
+				t.accepted++
+			}
+			if entry.Time.After(t.last) {
+				t.last = entry.Time
+			}
+		}
+	}
+
+	if profile != nil {
+		var most uint64
+		for _, count := range profile.Tags {
+			if count > most {
+				most = count
+			}
+		}
+		for tag, count := range profile.Tags {
+			model.coverage[tag] = float64(count) / float64(most)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].Time.Before(recent[j].Time) })
+	if len(recent) > RecentSubmissions {
+		recent = recent[len(recent)-RecentSubmissions:]
+	}
+	model.target, model.targetDetail = targetLevel(recent, profile)
+
+	return &model
+}
+
+// targetLevel is the level of the problems recently solved, one up if they
+// were mostly accepted and one down if they were mostly not
+func targetLevel(recent []history.Entry, profile *provider.Profile) (int, string) {
+	if len(recent) == 0 {
+		current := 0
+		if profile != nil {
+			// the hardest level at which a handful of problems were solved
+			for difficulty, count := range profile.Solved {
+				if l := level(difficulty); l > current && count >= 5 {
+					current = l
+				}
+			}
+		}
+		return current, "no recent submissions, following the problems you solved"
+	}
+
+	var accepted int
+	counts := map[int]int{}
+	for _, entry := range recent {
+		if entry.IsAccepted() {
+			accepted++
+		}
+		if l := level(entry.Difficulty); l >= 0 {
+			counts[l]++
+		}
+	}
+
+	current := 0
+	for l, count := range counts {
+		if count > counts[current] || (count == counts[current] && l > current) {
+			current = l
+		}
+	}
+
+	rate := float64(accepted) / float64(len(recent))
+	summary := fmt.Sprintf("%d/%d recent submissions accepted", accepted, len(recent))
+	switch {
+	case rate >= 0.7 && current+1 < len(Levels):
+		return current + 1, "stepping up, " + summary
+	case rate < 0.4 && current > 0:
+		return current - 1, "stepping down, " + summary
+	default:
+		return current, "keeping up, " + summary
+	}
+}
+
+// weakness is between 0 (mastered) and 1 (never solved)
+func (model *Model) weakness(tag string) (float64, string) {
+	t, ok := model.topics[tag]
+	if !ok {
+		t = &topic{}
+	}
+
+	// smoothed, so that a single submission does not make a topic a strength
+	// or a weakness
+	local := 1 - float64(t.accepted+1)/float64(t.submissions+2)
+	detail := fmt.Sprintf("%s, %d/%d submissions accepted", tag, t.accepted, t.submissions)
+	if t.submissions == 0 {
+		detail = fmt.Sprintf("%s, never submitted", tag)
+	}
+
+	coverage, ok := model.coverage[tag]
+	if !ok {
+		return local, detail
+	}
+	return (local + 1 - coverage) / 2, fmt.Sprintf("%s, %.0f%% as many solved as your strongest topic", detail, 100*coverage)
+}
+
+// staleness is between 0 (practiced today) and 1 (not for StaleDays or never)
+func (model *Model) staleness(tag string) (float64, string) {
+	t, ok := model.topics[tag]
+	if !ok || t.last.IsZero() {
+		return 1, fmt.Sprintf("%s, never practiced", tag)
+	}
+	days := model.now.Sub(t.last).Hours() / 24
+	detail := fmt.Sprintf("%s, last practiced %.0f days ago", tag, days)
+	if days < 1 {
+		detail = fmt.Sprintf("%s, practiced today", tag)
+	} else if days < 2 {
+		detail = fmt.Sprintf("%s, last practiced yesterday", tag)
+	}
+	return math.Min(1, math.Max(0, days/StaleDays)), detail
+}
+
+// WeakestTags returns the tags practiced so far, the weakest first
+func (model *Model) WeakestTags() []string {
+	var tags []string
+	for tag := range model.topics {
+		tags = append(tags, tag)
+	}
+	for tag := range model.coverage {
+		if _, ok := model.topics[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	weakness := map[string]float64{}
+	for _, tag := range tags {
+		weakness[tag], _ = model.weakness(tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if weakness[tags[i]] != weakness[tags[j]] {
+			return weakness[tags[i]] > weakness[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	return tags
+}
+
+// Target is the difficulty to aim for, and why
+func (model *Model) Target() (string, string) {
+	return Levels[model.target], model.targetDetail
+}
+
+// Score rates how good a next problem summary is, explaining why
+func (model *Model) Score(summary provider.ChallengeSummary) Candidate {
+	candidate := Candidate{Summary: summary}
+
+	weakness, weaknessDetail := 0.5, "no topics known"
+	staleness, stalenessDetail := 0.5, "no topics known"
+	for i, tag := range summary.Details.Tags {
+		w, detail := model.weakness(tag)
+		if i == 0 || w > weakness {
+			weakness, weaknessDetail = w, detail
+		}
+		s, detail := model.staleness(tag)
+		if i == 0 || s > staleness {
+			staleness, stalenessDetail = s, detail
+		}
+	}
+
+	fit := 0.5
+	target, targetDetail := model.Target()
+	fitDetail := fmt.Sprintf("unknown difficulty, aiming for %s (%s)", target, targetDetail)
+	if l := level(summary.Details.Difficulty); l >= 0 {
+		distance := math.Abs(float64(l - model.target))
+		fit = math.Max(0, 1-distance/2)
+		fitDetail = fmt.Sprintf("%s, aiming for %s (%s)", strings.ToLower(summary.Details.Difficulty), target, targetDetail)
+	}
+
+	candidate.Factors = []Factor{
+		{"weakness", weakness, weaknessDetail},
+		{"difficulty", fit, fitDetail},
+		{"recency", staleness, stalenessDetail},
+	}
+	candidate.Score = WeaknessWeight*weakness + DifficultyWeight*fit + RecencyWeight*staleness
+
+	if summary.Status == provider.Attempted {
+		candidate.Score += AttemptedBonus
+		candidate.Factors = append(candidate.Factors, Factor{"attempted", 1, "attempted before, not solved yet"})
+	}
+
+	return candidate
+}
+
+// Rank scores the summaries which are not solved yet, the best first
+func (model *Model) Rank(summaries []provider.ChallengeSummary) []Candidate {
+	var candidates []Candidate
+	seen := map[string]bool{}
+	for _, summary := range summaries {
+		slug := summary.Filters.GetFilterOrDefault("slug")
+		if summary.Status == provider.Solved || model.solved[slug] || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		candidates = append(candidates, model.Score(summary))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
@@ -0,0 +1,214 @@
+// Package review schedules solved problems for review, following the SM-2
+// spaced repetition algorithm
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/history"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const Filename = "review.json"
+
+const (
+	DefaultEase float64 = 2.5
+	MinEase             = 1.3
+)
+
+// MaxGrade is the best grade of a review, 0 being a complete blackout and any
+// grade below 3 a failure to recall the solution
+const MaxGrade = 5
+
+type Card struct {
+	Provider    string            `json:"provider"`
+	Identity    map[string]string `json:"identity"`
+	Title       string            `json:"title,omitempty"`
+	Difficulty  string            `json:"difficulty,omitempty"`
+	Lang        string            `json:"lang,omitempty"`
+	Ease        float64           `json:"ease"`
+	Interval    int               `json:"interval_days"`
+	Repetitions int               `json:"repetitions"` // successful ones in a row
+	Due         time.Time         `json:"due"`
+	Reviewed    time.Time         `json:"reviewed,omitempty"` // when last graded, or first solved
+	Pending     bool              `json:"pending,omitempty"`  // checked out for review, waiting for a grade
+}
+
+func (card *Card) Problem() string {
+	return fmt.Sprintf("%s/%s/%s", card.Provider, card.Identity["contest"], card.Identity["slug"])
+}
+
+func (card *Card) IsDue(now time.Time) bool {
+	return !card.Due.After(now)
+}
+
+// Grade reschedules the card after a review graded between 0 and MaxGrade
+func (card *Card) Grade(grade int, now time.Time) error {
+	if grade < 0 || grade > MaxGrade {
+		return fmt.Errorf("invalid grade: %d (expected 0 to %d)", grade, MaxGrade)
+	}
+
+	if grade < 3 {
+		card.Repetitions = 0
+		card.Interval = 1
+	} else {
+		card.Repetitions++
+		switch card.Repetitions {
+		case 1:
+			card.Interval = 1
+		case 2:
+			card.Interval = 6
+		default:
+			card.Interval = int(math.Round(float64(card.Interval) * card.Ease))
+		}
+	}
+
+	miss := float64(MaxGrade - grade)
+	card.Ease = math.Max(MinEase, card.Ease+0.1-miss*(0.08+miss*0.02))
+
+	card.Reviewed = now
+	card.Due = now.AddDate(0, 0, card.Interval)
+	card.Pending = false
+	return nil
+}
+
+type Queue struct {
+	Cards []Card `json:"cards"`
+}
+
+// Path is where the queue is kept, in the configuration directory
+func Path(configDir string) string {
+	return filepath.Join(configDir, Filename)
+}
+
+// Load reads the queue at path, a missing queue being an empty one
+func Load(path string) (*Queue, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Queue{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse reads a queue, as written by Encode
+func Parse(data []byte) (*Queue, error) {
+	queue := Queue{}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("invalid review queue: %s", err)
+	}
+	for i := range queue.Cards {
+		if queue.Cards[i].Ease == 0 {
+			queue.Cards[i].Ease = DefaultEase
+		}
+	}
+	return &queue, nil
+}
+
+func (queue *Queue) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	// write then rename, so that the queue is never left half-written
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := queue.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Encode writes the queue as JSON, as read by Parse
+func (queue *Queue) Encode(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(queue)
+}
+
+// Find returns the card of problem, as returned by Card.Problem, or nil
+func (queue *Queue) Find(problem string) *Card {
+	for i := range queue.Cards {
+		if queue.Cards[i].Problem() == problem {
+			return &queue.Cards[i]
+		}
+	}
+	return nil
+}
+
+// Sync adds the problems solved in the history which are not in the queue
+// yet, each due a day after it was first solved. It returns how many were
+// added.
+func (queue *Queue) Sync(entries []history.Entry) int {
+	added := 0
+	for _, entry := range entries {
+		if !entry.IsAccepted() || queue.Find(entry.Problem()) != nil {
+			continue
+		}
+
+		queue.Cards = append(queue.Cards, Card{
+			Provider:    entry.Provider,
+			Identity:    entry.Identity,
+			Title:       entry.Title,
+			Difficulty:  entry.Difficulty,
+			Lang:        entry.Lang,
+			Ease:        DefaultEase,
+			Interval:    1,
+			Repetitions: 1,
+			Due:         entry.Time.AddDate(0, 0, 1),
+			Reviewed:    entry.Time,
+		})
+		added++
+	}
+	return added
+}
+
+// Due returns the cards of backend due by now, the most overdue first
+func (queue *Queue) Due(backend string, now time.Time) []*Card {
+	var output []*Card
+	for i := range queue.Cards {
+		card := &queue.Cards[i]
+		if card.Provider == backend && card.IsDue(now) {
+			output = append(output, card)
+		}
+	}
+	sort.SliceStable(output, func(i, j int) bool {
+		return output[i].Due.Before(output[j].Due)
+	})
+	return output
+}
+
+// Merge adds the cards of other to the queue, keeping the most recently
+// reviewed card of problems in both. It returns how many cards were added or
+// updated.
+func (queue *Queue) Merge(other *Queue) int {
+	changed := 0
+	for _, card := range other.Cards {
+		found := queue.Find(card.Problem())
+		if found == nil {
+			queue.Cards = append(queue.Cards, card)
+			changed++
+		} else if card.Reviewed.After(found.Reviewed) {
+			*found = card
+			changed++
+		}
+	}
+	return changed
+}
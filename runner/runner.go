@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/brokad/tinycode/sandbox"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type Program struct {
+	Path     string
+	Lang     provider.Lang
+	Limits   sandbox.Limits
+	buildDir string
+	command  []string
+}
+
+// LangFromPath recovers the language of a source file from its extension
+func LangFromPath(path string) (*provider.Lang, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return nil, fmt.Errorf("cannot guess the language of a file without an extension: %s", path)
+	}
+	return provider.ParseExt(ext)
+}
+
+func compile(args ...string) error {
+	log.Printf("compiling: %s", strings.Join(args, " "))
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stderr
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("compilation failed: %s\n%s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// SchemaPath is the path of the schema.sql file next to the SQL solution at
+// path, empty if there is none. It creates the tables of the problem, as the
+// samples of LeetCode do for themselves.
+func SchemaPath(path string) string {
+	schema := filepath.Join(filepath.Dir(path), "schema.sql")
+	if _, err := os.Stat(schema); err != nil {
+		return ""
+	}
+	return schema
+}
+
+// Build compiles the source file at path if lang needs compiling, and returns a
+// Program ready to be run. The program should be closed once done with.
+func Build(path string, lang provider.Lang) (*Program, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buildDir, err := os.MkdirTemp("", "tinycode-build-")
+	if err != nil {
+		return nil, err
+	}
+
+	program := Program{Path: path, Lang: lang, buildDir: buildDir}
+	binary := filepath.Join(buildDir, "solution")
+
+	switch lang.String() {
+	case provider.Cpp, provider.Cpp14:
+		err = compile("g++", "-std=c++17", "-O2", "-o", binary, path)
+		program.command = []string{binary}
+	case provider.C:
+		err = compile("gcc", "-O2", "-o", binary, path, "-lm")
+		program.command = []string{binary}
+	case provider.Rust:
+		err = compile("rustc", "-O", "-o", binary, path)
+		program.command = []string{binary}
+	case provider.Golang:
+		err = compile("go", "build", "-o", binary, path)
+		program.command = []string{binary}
+	case provider.Haskell:
+		err = compile("ghc", "-O2", "-outputdir", buildDir, "-o", binary, path)
+		program.command = []string{binary}
+	case provider.Java, provider.Java8, provider.Java15:
+		program.command = []string{"java", path}
+	case provider.Python3, provider.PythonData:
+		program.command = []string{"python3", path}
+	case provider.Python:
+		program.command = []string{"python", path}
+	case provider.Pypy:
+		program.command = []string{"pypy", path}
+	case provider.Pypy3:
+		program.command = []string{"pypy3", path}
+	case provider.JavaScript:
+		program.command = []string{"node", path}
+	case provider.Ruby:
+		program.command = []string{"ruby", path}
+	case provider.Perl:
+		program.command = []string{"perl", path}
+	case provider.Php:
+		program.command = []string{"php", path}
+	case provider.Bash:
+		program.command = []string{"bash", path}
+	case provider.MySQL, provider.MsSQL, provider.PostgreSQL, provider.OracleSQL, provider.DB2:
+		// the input creates and fills the tables in memory, then the query
+		// runs on them: this is only a quick check, as sqlite does not speak
+		// every dialect
+		program.command = []string{"sqlite3", "-bail", "-header", "-column"}
+		if schema := SchemaPath(path); schema != "" {
+			program.command = append(program.command, "-cmd", fmt.Sprintf(".read '%s'", schema))
+		}
+		program.command = append(program.command, "-cmd", ".read /dev/stdin", ":memory:", fmt.Sprintf(".read '%s'", path))
+	default:
+		err = fmt.Errorf("don't know how to run %s locally", lang.Pretty())
+	}
+
+	if err != nil {
+		os.RemoveAll(buildDir)
+		return nil, err
+	}
+
+	return &program, nil
+}
+
+func (program *Program) Close() error {
+	return os.RemoveAll(program.buildDir)
+}
+
+// Run executes the program in a sandbox with the given arguments, feeding it
+// stdin. An error is only returned if the program could not be run at all, or
+// if ctx was cancelled.
+func (program *Program) Run(ctx context.Context, stdin string, args ...string) (*sandbox.Verdict, error) {
+	command := append(append([]string{}, program.command...), args...)
+	return sandbox.Run(ctx, command, filepath.Dir(program.Path), strings.NewReader(stdin), program.Limits)
+}
+
+// SameOutput compares the outputs of two programs, ignoring trailing whitespace
+// on every line as well as trailing empty lines
+func SameOutput(a string, b string) bool {
+	normalize := func(s string) string {
+		var lines []string
+		for _, line := range strings.Split(s, "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+		return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	}
+	return normalize(a) == normalize(b)
+}
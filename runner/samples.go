@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sample is a test input, along with its expected output if known
+type Sample struct {
+	Path     string
+	Input    string
+	Expected *string
+}
+
+// expectedExts are the extensions tried, in order, for the expected output of
+// a sample `name.in`
+var expectedExts = []string{".out", ".ans"}
+
+func readSamples(dir string) ([]Sample, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.in"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var samples []Sample
+	for _, path := range paths {
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		sample := Sample{Path: path, Input: string(input)}
+
+		for _, ext := range expectedExts {
+			expected, err := os.ReadFile(strings.TrimSuffix(path, ".in") + ext)
+			if err == nil {
+				s := string(expected)
+				sample.Expected = &s
+				break
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// FindSamples looks for `*.in` files (and their `.out` or `.ans` counterparts)
+// next to the solution at path, and if there are none in the parent directories
+// up to root. root may be empty to only look next to the solution.
+func FindSamples(path string, root string) ([]Sample, error) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if root != "" {
+		if root, err = filepath.Abs(root); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		samples, err := readSamples(dir)
+		if err != nil || len(samples) != 0 {
+			return samples, err
+		}
+
+		parent := filepath.Dir(dir)
+		if root == "" || dir == root || parent == dir || !strings.HasPrefix(parent, root) {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"github.com/brokad/tinycode/sandbox"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type StressConfig struct {
+	Iterations uint64
+	Seed       int64
+	Jobs       int
+}
+
+// Mismatch is an input on which the solution and the reference disagree
+type Mismatch struct {
+	Seed     int64
+	Input    string
+	Expected *sandbox.Verdict
+	Actual   *sandbox.Verdict
+}
+
+// check runs both the solution and the reference on input, returning a
+// Mismatch if they disagree
+func check(ctx context.Context, solution *Program, brute *Program, input string) (*Mismatch, error) {
+	expected, err := brute.Run(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("reference solution did not complete: %s", err)
+	}
+
+	if !expected.HasSucceeded() {
+		return nil, fmt.Errorf("reference solution failed (%s, %s):\n%s", expected.Status, expected.Describe(), expected.Stderr)
+	}
+
+	actual, err := solution.Run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actual.HasSucceeded() || !SameOutput(expected.Stdout, actual.Stdout) {
+		return &Mismatch{Input: input, Expected: expected, Actual: actual}, nil
+	}
+
+	return nil, nil
+}
+
+// Stress feeds inputs produced by gen (which is passed a seed as its only
+// argument) to both solution and brute, until they disagree or the configured
+// number of iterations is exhausted. It returns the number of iterations that
+// were completed.
+func Stress(ctx context.Context, solution *Program, brute *Program, gen *Program, config StressConfig) (*Mismatch, uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var next uint64
+	var done uint64
+
+	var once sync.Once
+	var mismatch *Mismatch
+	var failure error
+	stop := func(m *Mismatch, err error) {
+		once.Do(func() {
+			mismatch = m
+			failure = err
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < config.Jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				iteration := atomic.AddUint64(&next, 1) - 1
+				if iteration >= config.Iterations {
+					return
+				}
+
+				seed := config.Seed + int64(iteration)
+				generated, err := gen.Run(ctx, "", fmt.Sprintf("%d", seed))
+				if err != nil {
+					if ctx.Err() == nil {
+						stop(nil, fmt.Errorf("generator did not complete on seed %d: %s", seed, err))
+					}
+					return
+				} else if !generated.HasSucceeded() {
+					stop(nil, fmt.Errorf("generator failed on seed %d (%s, %s):\n%s", seed, generated.Status, generated.Describe(), generated.Stderr))
+					return
+				}
+
+				found, err := check(ctx, solution, brute, generated.Stdout)
+				if err != nil {
+					if ctx.Err() == nil {
+						stop(nil, fmt.Errorf("seed %d: %s", seed, err))
+					}
+					return
+				} else if found != nil {
+					found.Seed = seed
+					stop(found, nil)
+					return
+				}
+
+				atomic.AddUint64(&done, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return mismatch, done, failure
+}
+
+// Minimize attempts to shrink the input of mismatch by removing chunks of
+// lines, keeping those removals on which the reference still succeeds and the
+// solution still disagrees. At most budget candidates are tried.
+func Minimize(ctx context.Context, solution *Program, brute *Program, mismatch *Mismatch, budget int) *Mismatch {
+	best := mismatch
+	lines := strings.Split(strings.TrimRight(mismatch.Input, "\n"), "\n")
+
+	for chunk := len(lines) / 2; chunk > 0 && budget > 0; chunk /= 2 {
+		for start := 0; start < len(lines) && budget > 0; {
+			end := start + chunk
+			if end > len(lines) {
+				end = len(lines)
+			}
+
+			candidate := append(append([]string{}, lines[:start]...), lines[end:]...)
+			if len(candidate) == 0 {
+				break
+			}
+
+			budget--
+			found, err := check(ctx, solution, brute, strings.Join(candidate, "\n")+"\n")
+			if err != nil {
+				if ctx.Err() != nil {
+					return best
+				}
+				log.Printf("discarding candidate: %s", err)
+			}
+
+			if found != nil {
+				found.Seed = mismatch.Seed
+				best = found
+				lines = candidate
+			} else {
+				start = end
+			}
+		}
+	}
+
+	return best
+}
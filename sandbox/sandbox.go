@@ -0,0 +1,172 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type Limits struct {
+	CPUTime  time.Duration // 0 for no limit
+	WallTime time.Duration // 0 for no limit
+	Memory   uint64        // in bytes, 0 for no limit
+	Output   uint64        // in bytes, 0 for no limit
+	Isolate  bool          // whether to run in new namespaces, without network access
+}
+
+type Status int
+
+const (
+	Ok Status = iota
+	TimeLimitExceeded
+	MemoryLimitExceeded
+	OutputLimitExceeded
+	RuntimeError
+)
+
+func (status Status) String() string {
+	switch status {
+	case Ok:
+		return "ok"
+	case TimeLimitExceeded:
+		return "time limit exceeded"
+	case MemoryLimitExceeded:
+		return "memory limit exceeded"
+	case OutputLimitExceeded:
+		return "output limit exceeded"
+	case RuntimeError:
+		return "runtime error"
+	default:
+		panic(fmt.Sprintf("unknown sandbox status: %d", status))
+	}
+}
+
+type Verdict struct {
+	Status   Status
+	ExitCode int
+	Signal   syscall.Signal // 0 if the process was not killed by a signal
+	CPUTime  time.Duration
+	WallTime time.Duration
+	Memory   uint64 // peak resident memory, in bytes
+	Stdout   string
+	Stderr   string
+}
+
+func (verdict *Verdict) HasSucceeded() bool {
+	return verdict.Status == Ok
+}
+
+// Describe summarizes how the process ended, e.g. `killed by signal 11
+// (segmentation fault)`
+func (verdict *Verdict) Describe() string {
+	if verdict.Signal != 0 {
+		return fmt.Sprintf("killed by signal %d (%s)", int(verdict.Signal), verdict.Signal)
+	} else {
+		return fmt.Sprintf("exited with code %d", verdict.ExitCode)
+	}
+}
+
+// limitedBuffer collects output up to a limit, calling onExceeded (once) when
+// more is written to it
+type limitedBuffer struct {
+	buf        bytes.Buffer
+	limit      uint64
+	exceeded   bool
+	onExceeded func()
+	mutex      sync.Mutex
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.exceeded {
+		return len(p), nil
+	}
+
+	if b.limit != 0 && uint64(b.buf.Len()+len(p)) > b.limit {
+		b.buf.Write(p[:b.limit-uint64(b.buf.Len())])
+		b.exceeded = true
+		b.onExceeded()
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Exceeded() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.exceeded
+}
+
+func (b *limitedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// outOfMemoryMarkers are printed by common runtimes when allocations fail,
+// matched regardless of case (e.g. "Cannot allocate memory" or Go's "cannot
+// allocate memory")
+var outOfMemoryMarkers = []string{
+	"std::bad_alloc",
+	"memoryerror",
+	"outofmemoryerror",
+	"out of memory",
+	"cannot allocate memory",
+	"memory allocation of",
+}
+
+// classify derives the status of a finished process from how it ended and the
+// resources it used
+func classify(verdict *Verdict, limits Limits, timedOut bool, outputExceeded bool, oomKilled bool) Status {
+	if outputExceeded {
+		return OutputLimitExceeded
+	}
+
+	if oomKilled {
+		return MemoryLimitExceeded
+	}
+
+	if timedOut || (limits.CPUTime != 0 && verdict.CPUTime >= limits.CPUTime) {
+		return TimeLimitExceeded
+	}
+
+	failed := verdict.Signal != 0 || verdict.ExitCode != 0
+
+	// Without cgroups, running out of memory shows up as failed allocations,
+	// so failures close to the limit or reporting as much are attributed to it
+	if failed && limits.Memory != 0 {
+		if verdict.Memory*10 >= limits.Memory*9 {
+			return MemoryLimitExceeded
+		}
+		stderr := strings.ToLower(verdict.Stderr)
+		for _, marker := range outOfMemoryMarkers {
+			if strings.Contains(stderr, marker) {
+				return MemoryLimitExceeded
+			}
+		}
+	}
+
+	if failed {
+		return RuntimeError
+	}
+
+	return Ok
+}
+
+// Run executes command in dir, feeding it stdin and enforcing limits. An error
+// is only returned if the command could not be run at all, or if ctx was
+// cancelled.
+func Run(ctx context.Context, command []string, dir string, stdin io.Reader, limits Limits) (*Verdict, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+	return run(ctx, command, dir, stdin, limits)
+}
@@ -0,0 +1,465 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+var cgroupCounter uint64
+
+type cgroup struct {
+	path string
+}
+
+// ownCgroup finds the cgroup v2 of the current process, relative to the root
+// of the hierarchy
+func ownCgroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+
+	return "", fmt.Errorf("process does not belong to a cgroup v2")
+}
+
+var (
+	parentOnce sync.Once
+	parentPath string
+	parentErr  error
+)
+
+// enableControllers enables the memory and pids controllers for the children
+// of the cgroup at path
+func enableControllers(path string) error {
+	return os.WriteFile(filepath.Join(path, "cgroup.subtree_control"), []byte("+memory +pids"), 0644)
+}
+
+// cgroupParent returns the cgroup in which the cgroups of runs are created,
+// that of the current process. Controllers can only be enabled for the
+// children of a cgroup which holds no process itself, so the current process
+// first moves to a leaf of its cgroup, which is left there for the following
+// runs.
+func cgroupParent() (string, error) {
+	parentOnce.Do(func() {
+		self, err := ownCgroup()
+		if err != nil {
+			parentErr = err
+			return
+		}
+
+		parent := filepath.Join(cgroupRoot, self)
+		err = enableControllers(parent)
+		if errors.Is(err, unix.EBUSY) {
+			leaf := filepath.Join(parent, "tinycode")
+			if err := os.Mkdir(leaf, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+				parentErr = err
+				return
+			}
+			if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+				parentErr = fmt.Errorf("could not move to a leaf cgroup: %s", err)
+				return
+			}
+
+			if err = enableControllers(parent); errors.Is(err, unix.EBUSY) {
+				err = fmt.Errorf("cgroup %s holds other processes, try running in a cgroup of its own, e.g. with systemd-run --user --scope -p Delegate=yes", self)
+			}
+		}
+		if err != nil {
+			parentErr = fmt.Errorf("could not enable memory controller: %s", err)
+			return
+		}
+
+		parentPath = parent
+	})
+
+	return parentPath, parentErr
+}
+
+// newCgroup creates a cgroup enforcing the memory limit, nested in the cgroup
+// of the current process. This requires cgroups v2, and for the current cgroup
+// to be delegated to us.
+func newCgroup(limits Limits) (*cgroup, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("cgroups v2 not available")
+	}
+
+	parent, err := cgroupParent()
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("tinycode-%d-%d", os.Getpid(), atomic.AddUint64(&cgroupCounter, 1))
+	cg := cgroup{path: filepath.Join(parent, name)}
+	if err := os.Mkdir(cg.path, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := cg.write("memory.max", strconv.FormatUint(limits.Memory, 10)); err != nil {
+		cg.remove()
+		return nil, err
+	}
+
+	if err := cg.write("memory.swap.max", "0"); err != nil {
+		log.Printf("could not disable swap: %s", err)
+	}
+
+	return &cg, nil
+}
+
+func (cg *cgroup) write(name string, value string) error {
+	return os.WriteFile(filepath.Join(cg.path, name), []byte(value), 0644)
+}
+
+func (cg *cgroup) read(name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(cg.path, name))
+	return strings.TrimSpace(string(content)), err
+}
+
+func (cg *cgroup) oomKilled() bool {
+	events, err := cg.read("memory.events")
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(events, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "oom_kill" && fields[1] != "0" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cg *cgroup) peak() uint64 {
+	peak, err := cg.read("memory.peak")
+	if err != nil {
+		return 0
+	}
+	value, _ := strconv.ParseUint(peak, 10, 64)
+	return value
+}
+
+func (cg *cgroup) remove() {
+	// The cgroup can only be removed once the kernel is done reaping its
+	// processes, which may take a little while after they are killed
+	for attempt := 0; attempt < 10; attempt++ {
+		if err := os.Remove(cg.path); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Printf("could not remove cgroup: %s", cg.path)
+}
+
+func sysProcAttr(isolate bool) *syscall.SysProcAttr {
+	attr := syscall.SysProcAttr{Setpgid: true}
+
+	if isolate {
+		attr.Cloneflags = syscall.CLONE_NEWUSER |
+			syscall.CLONE_NEWNS |
+			syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWIPC |
+			syscall.CLONE_NEWUTS
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+		attr.GidMappingsEnableSetgroups = false
+	}
+
+	return &attr
+}
+
+// limitHelper is the first argument with which tinycode runs itself to apply
+// rlimits to a command before executing it, so that the command never runs
+// without them
+const limitHelper = "__sandbox-exec"
+
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == limitHelper {
+		if err := limitAndExec(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "tinycode: could not run command: %s\n", err)
+			os.Exit(127)
+		}
+	}
+}
+
+// limitAndExec applies the rlimits in args, the cpu time in seconds and the
+// data and file sizes in bytes (0 for no limit), then executes the program at
+// the path which follows them with the remaining arguments
+func limitAndExec(args []string) error {
+	if len(args) < 5 {
+		return fmt.Errorf("usage: %s CPU DATA FSIZE PATH ARGV...", limitHelper)
+	}
+
+	var values [3]uint64
+	for i := range values {
+		value, err := strconv.ParseUint(args[i], 10, 64)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	if err := setRlimits(values[0], values[1], values[2]); err != nil {
+		return fmt.Errorf("could not set resource limits: %s", err)
+	}
+
+	return syscall.Exec(args[3], args[4:], os.Environ())
+}
+
+func setRlimits(cpu uint64, data uint64, fsize uint64) error {
+	set := func(resource int, soft uint64, hard uint64) error {
+		return unix.Setrlimit(resource, &unix.Rlimit{Cur: soft, Max: hard})
+	}
+
+	if err := set(unix.RLIMIT_CORE, 0, 0); err != nil {
+		return err
+	}
+
+	if cpu != 0 {
+		// The soft limit sends SIGXCPU, the hard limit SIGKILL in case the
+		// former is handled or ignored
+		if err := set(unix.RLIMIT_CPU, cpu, cpu+1); err != nil {
+			return err
+		}
+	}
+
+	if data != 0 {
+		// Not RLIMIT_AS: the Go runtime, V8 and the JVM reserve much more
+		// address space than they use, and would not even start
+		if err := set(unix.RLIMIT_DATA, data, data); err != nil {
+			return err
+		}
+	}
+
+	if fsize != 0 {
+		if err := set(unix.RLIMIT_FSIZE, fsize, fsize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookPath finds the program of a command run in dir
+func lookPath(name string, dir string) (string, error) {
+	if !strings.Contains(name, "/") {
+		return exec.LookPath(name)
+	}
+
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	stat, err := os.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	if stat.IsDir() || stat.Mode()&0111 == 0 {
+		return "", fmt.Errorf("not an executable: %s", name)
+	}
+	return name, nil
+}
+
+// helperCommand runs command through the limit helper. Memory is only limited
+// with an rlimit if it is not with a cgroup.
+func helperCommand(command []string, dir string, limits Limits, memory bool) ([]string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := lookPath(command[0], dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpu, data uint64
+	if limits.CPUTime != 0 {
+		cpu = uint64((limits.CPUTime + time.Second - 1) / time.Second)
+	}
+	if memory {
+		data = limits.Memory
+	}
+
+	helper := []string{
+		self, limitHelper,
+		strconv.FormatUint(cpu, 10), strconv.FormatUint(data, 10), strconv.FormatUint(limits.Output, 10),
+		path,
+	}
+	return append(helper, command...), nil
+}
+
+// start starts command through the limit helper, directly in cg unless it is
+// nil. It returns whether the process is in cg, which it is not if it had to
+// be started without it.
+func start(command []string, dir string, stdin io.Reader, stdout io.Writer, stderr io.Writer, limits Limits, isolate bool, cg *cgroup) (*exec.Cmd, bool, error) {
+	helper, err := helperCommand(command, dir, limits, cg == nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cmd := exec.Command(helper[0], helper[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = sysProcAttr(isolate)
+
+	if cg != nil {
+		fd, err := unix.Open(cg.path, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		defer unix.Close(fd)
+
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = fd
+	}
+
+	err = cmd.Start()
+	if err != nil && isolate && (errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOSPC)) {
+		// unprivileged user namespaces are disabled on some systems
+		log.Printf("could not create namespaces, running without isolation: %s", err)
+		return start(command, dir, stdin, stdout, stderr, limits, false, cg)
+	}
+	if err != nil && cg != nil && errors.Is(err, syscall.ENOSYS) {
+		// starting in a cgroup takes clone3, from Linux 5.7, which some
+		// seccomp profiles filter out
+		log.Printf("could not start in a cgroup, falling back to rlimits: %s", err)
+		return start(command, dir, stdin, stdout, stderr, limits, isolate, nil)
+	}
+
+	return cmd, cg != nil, err
+}
+
+func run(ctx context.Context, command []string, dir string, stdin io.Reader, limits Limits) (*Verdict, error) {
+	var cg *cgroup
+	if limits.Memory != 0 {
+		var err error
+		if cg, err = newCgroup(limits); err != nil {
+			log.Printf("not using cgroups, falling back to rlimits: %s", err)
+			cg = nil
+		} else {
+			defer cg.remove()
+		}
+	}
+
+	var mutex sync.Mutex
+	var process *os.Process
+	kill := func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if process != nil {
+			syscall.Kill(-process.Pid, syscall.SIGKILL)
+		}
+	}
+
+	stdout := &limitedBuffer{limit: limits.Output, onExceeded: kill}
+	stderr := &limitedBuffer{limit: limits.Output, onExceeded: kill}
+
+	startTime := time.Now()
+	cmd, inCgroup, err := start(command, dir, stdin, stdout, stderr, limits, limits.Isolate, cg)
+	if err != nil {
+		return nil, err
+	}
+	if !inCgroup {
+		cg = nil
+	}
+
+	mutex.Lock()
+	process = cmd.Process
+	mutex.Unlock()
+
+	var wallExceeded int32
+	done := make(chan struct{})
+	go func() {
+		var timer <-chan time.Time
+		if limits.WallTime != 0 {
+			t := time.NewTimer(limits.WallTime)
+			defer t.Stop()
+			timer = t.C
+		}
+
+		select {
+		case <-done:
+		case <-timer:
+			atomic.StoreInt32(&wallExceeded, 1)
+			kill()
+		case <-ctx.Done():
+			kill()
+		}
+	}()
+
+	waitErr := cmd.Wait()
+	wallTime := time.Since(startTime)
+	close(done)
+
+	// Reap whatever the process left behind in its group
+	kill()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, waitErr
+	}
+
+	state := cmd.ProcessState
+	verdict := Verdict{
+		ExitCode: state.ExitCode(),
+		CPUTime:  state.UserTime() + state.SystemTime(),
+		WallTime: wallTime,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		verdict.Signal = status.Signal()
+	}
+
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		verdict.Memory = uint64(rusage.Maxrss) * 1024
+	}
+
+	oomKilled := false
+	if cg != nil {
+		if peak := cg.peak(); peak > verdict.Memory {
+			verdict.Memory = peak
+		}
+		oomKilled = cg.oomKilled()
+	}
+
+	timedOut := atomic.LoadInt32(&wallExceeded) == 1 || verdict.Signal == syscall.SIGXCPU
+	outputExceeded := stdout.Exceeded() || stderr.Exceeded() || verdict.Signal == syscall.SIGXFSZ
+	verdict.Status = classify(&verdict, limits, timedOut, outputExceeded, oomKilled)
+
+	return &verdict, nil
+}
@@ -0,0 +1,88 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// run only enforces the wall time and output limits outside of Linux
+func run(ctx context.Context, command []string, dir string, stdin io.Reader, limits Limits) (*Verdict, error) {
+	if limits.CPUTime != 0 || limits.Memory != 0 || limits.Isolate {
+		log.Printf("cpu time, memory and isolation are only enforced on linux")
+	}
+
+	var cmd *exec.Cmd
+	kill := func() {
+		if cmd != nil && cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	}
+
+	stdout := &limitedBuffer{limit: limits.Output, onExceeded: kill}
+	stderr := &limitedBuffer{limit: limits.Output, onExceeded: kill}
+
+	cmd = exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var wallExceeded int32
+	done := make(chan struct{})
+	go func() {
+		var timer <-chan time.Time
+		if limits.WallTime != 0 {
+			t := time.NewTimer(limits.WallTime)
+			defer t.Stop()
+			timer = t.C
+		}
+
+		select {
+		case <-done:
+		case <-timer:
+			atomic.StoreInt32(&wallExceeded, 1)
+			kill()
+		case <-ctx.Done():
+			kill()
+		}
+	}()
+
+	startTime := time.Now()
+	waitErr := cmd.Wait()
+	wallTime := time.Since(startTime)
+	close(done)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return nil, waitErr
+	}
+
+	state := cmd.ProcessState
+	verdict := Verdict{
+		ExitCode: state.ExitCode(),
+		CPUTime:  state.UserTime() + state.SystemTime(),
+		WallTime: wallTime,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+
+	outputExceeded := stdout.Exceeded() || stderr.Exceeded()
+	verdict.Status = classify(&verdict, limits, atomic.LoadInt32(&wallExceeded) == 1, outputExceeded, false)
+
+	return &verdict, nil
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/htmlpage"
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlTemplate shows the totals as cards, and each breakdown as a bar chart
+const htmlTemplate = `{{define "title"}}tinycode stats: {{.Report.Provider}}{{end}}
+{{define "style"}}h2 { font-size: 1.15em; margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: .3em; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; margin-top: 1.5em; }
+.card { flex: 1 1 9em; border: 1px solid #ddd; border-radius: 6px; padding: .8em 1em; }
+.card .value { font-size: 1.6em; font-weight: bold; }
+.card .label { color: #777; font-size: .85em; }
+td.bar { width: 50%; }
+.bar div { background: #4c9be8; height: .8em; border-radius: 2px; }
+.weak div { background: #e8704c; }
+{{end}}
+{{define "counts"}}<table>{{$max := maxCount .}}{{range .}}
+  <tr><td>{{.Name}}</td><td class="count">{{.Count}}</td><td class="bar"><div style="width: {{width .Count $max}}%"></div></td></tr>{{end}}
+</table>{{end}}
+{{define "body"}}<h1>Progress on {{.Report.Provider}}</h1>
+<p class="subtitle">{{if .Report.Profile}}{{.Report.Profile.Username}}, as{{else}}As{{end}} of {{.Generated}}</p>
+
+<div class="cards">
+  <div class="card"><div class="value">{{.Report.Solved}}</div><div class="label">solved locally</div></div>
+  <div class="card"><div class="value">{{.Report.Attempted}}</div><div class="label">attempted, not solved</div></div>
+  <div class="card"><div class="value">{{percent .Report.AcceptanceRate}}</div><div class="label">acceptance ({{.Report.Accepted}}/{{.Report.Submissions}})</div></div>
+  <div class="card"><div class="value">{{.Report.CurrentStreak}}d</div><div class="label">current streak (longest {{.Report.LongestStreak}}d)</div></div>
+  <div class="card"><div class="value">{{.MedianTimeToAccept}}</div><div class="label">median time to accept</div></div>
+</div>
+
+{{with .Report.ByDifficulty}}<h2>Solved by difficulty</h2>
+{{template "counts" .}}{{end}}
+
+{{with .Report.ByTag}}<h2>Solved by tag</h2>
+{{template "counts" .}}{{end}}
+
+{{with .Report.ByLang}}<h2>Solved by language</h2>
+{{template "counts" .}}{{end}}
+
+{{with .Report.WeakestTopics}}<h2>Weakest topics</h2>
+<table>
+  <tr><th>tag</th><th>accepted</th><th>acceptance</th></tr>{{range .}}
+  <tr><td>{{.Tag}}</td><td class="count">{{.Accepted}}/{{.Submissions}}</td><td class="bar weak"><div style="width: {{rate .AcceptanceRate}}%"></div></td></tr>{{end}}
+</table>{{end}}
+
+{{with .Report.Profile}}<h2>Profile of {{.Username}}</h2>
+{{with .Solved}}<table>
+  <tr><th>difficulty</th><th>solved</th>{{if $.Report.Profile.Untouched}}<th>left</th>{{end}}</tr>{{range $difficulty, $count := .}}
+  <tr><td>{{$difficulty}}</td><td class="count">{{$count}}</td>{{if $.Report.Profile.Untouched}}<td class="count">{{index $.Report.Profile.Untouched $difficulty}}</td>{{end}}</tr>{{end}}
+</table>{{end}}
+{{with .Badges}}<table>
+  <tr><th>badge</th><th>stars</th><th>solved</th></tr>{{range .}}
+  <tr><td>{{.Name}}</td><td class="count">{{.Stars}}</td><td class="count">{{.Solved}}/{{.Total}}</td></tr>{{end}}
+</table>{{end}}
+{{with .Scores}}<table>
+  <tr><th>track</th><th>score</th><th>rank</th></tr>{{range .}}
+  <tr><td>{{.Track}}</td><td class="count">{{printf "%.0f" .Score}}</td><td class="count">{{.Rank}}</td></tr>{{end}}
+</table>{{end}}{{end}}
+{{end}}`
+
+func maxCount(counts []Count) uint64 {
+	var output uint64
+	for _, count := range counts {
+		if count.Count > output {
+			output = count.Count
+		}
+	}
+	return output
+}
+
+// FormatDuration rounds d to a human-friendly precision, e.g. 2h30m or 3d4h
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "n/a"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+	case d < 24*time.Hour:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	default:
+		days := d / (24 * time.Hour)
+		hours := (d % (24 * time.Hour)).Round(time.Hour) / time.Hour
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+}
+
+func FormatPercent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", 100*rate)
+}
+
+// RenderHtml writes report as a standalone HTML page
+func RenderHtml(writer io.Writer, report Report, generated time.Time) error {
+	functions := template.FuncMap{
+		"maxCount": maxCount,
+		"percent":  FormatPercent,
+		"width": func(count uint64, max uint64) float64 {
+			if max == 0 {
+				return 0
+			}
+			return 100 * float64(count) / float64(max)
+		},
+		"rate": func(rate float64) float64 {
+			return 100 * rate
+		},
+	}
+
+	return htmlpage.Render(writer, "stats", functions, htmlTemplate, struct {
+		Report             Report
+		Generated          string
+		MedianTimeToAccept string
+	}{
+		report,
+		generated.Format("January 2, 2006"),
+		FormatDuration(report.MedianTimeToAcceptDuration()),
+	})
+}
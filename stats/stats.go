@@ -0,0 +1,251 @@
+// Package stats computes progress statistics from the local history, and from
+// what providers know of their users
+package stats
+
+import (
+	"github.com/brokad/tinycode/history"
+	"github.com/brokad/tinycode/provider"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WeakestTopicsCount is the number of topics reported as the weakest ones
+const WeakestTopicsCount = 5
+
+// MinTopicSubmissions is how many submissions a topic needs to be ranked among
+// the weakest ones
+const MinTopicSubmissions = 2
+
+type Count struct {
+	Name  string `json:"name"`
+	Count uint64 `json:"count"`
+}
+
+type Topic struct {
+	Tag            string  `json:"tag"`
+	Submissions    uint64  `json:"submissions"`
+	Accepted       uint64  `json:"accepted"`
+	AcceptanceRate float64 `json:"acceptance_rate"`
+}
+
+type Report struct {
+	Provider           string            `json:"provider"`
+	Profile            *provider.Profile `json:"profile,omitempty"`
+	Solved             uint64            `json:"solved"`
+	Attempted          uint64            `json:"attempted"` // but not solved
+	ByDifficulty       []Count           `json:"by_difficulty"`
+	ByTag              []Count           `json:"by_tag"`
+	ByLang             []Count           `json:"by_lang"`
+	Submissions        uint64            `json:"submissions"`
+	Accepted           uint64            `json:"accepted"`
+	AcceptanceRate     float64           `json:"acceptance_rate"` // 0 without submissions
+	CurrentStreak      int               `json:"current_streak_days"`
+	LongestStreak      int               `json:"longest_streak_days"`
+	MedianTimeToAccept int64             `json:"median_time_to_accept_s"` // 0 if unknown
+	WeakestTopics      []Topic           `json:"weakest_topics"`
+}
+
+func (report *Report) MedianTimeToAcceptDuration() time.Duration {
+	return time.Duration(report.MedianTimeToAccept) * time.Second
+}
+
+// sortCounts turns counts into a list, largest first
+func sortCounts(counts map[string]uint64) []Count {
+	output := []Count{}
+	for name, count := range counts {
+		output = append(output, Count{name, count})
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Count != output[j].Count {
+			return output[i].Count > output[j].Count
+		}
+		return output[i].Name < output[j].Name
+	})
+	return output
+}
+
+type problem struct {
+	firstSeen     time.Time
+	firstAccepted time.Time
+	difficulty    string
+	tags          []string
+	submitted     bool
+	langs         map[string]bool // of accepted submissions
+}
+
+func (p *problem) isSolved() bool {
+	return !p.firstAccepted.IsZero()
+}
+
+func day(t time.Time) time.Time {
+	year, month, d := t.Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, t.Location())
+}
+
+// streaks returns the current and longest runs of consecutive days out of days,
+// the current one ending today or yesterday
+func streaks(days map[time.Time]bool, now time.Time) (int, int) {
+	var sorted []time.Time
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	longest, run := 0, 0
+	for i, d := range sorted {
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	current := 0
+	d := day(now)
+	if !days[d] {
+		d = d.AddDate(0, 0, -1)
+	}
+	for days[d] {
+		current++
+		d = d.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	middle := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[middle-1] + durations[middle]) / 2
+	}
+	return durations[middle]
+}
+
+// Compute reports on the entries of backend in the history, as of now
+func Compute(backend string, entries []history.Entry, now time.Time) Report {
+	report := Report{Provider: backend}
+
+	problems := map[string]*problem{}
+	topics := map[string]*Topic{}
+	days := map[time.Time]bool{}
+
+	for _, entry := range entries {
+		if entry.Provider != backend {
+			continue
+		}
+
+		key := entry.Problem()
+		p, ok := problems[key]
+		if !ok {
+			p = &problem{firstSeen: entry.Time, langs: map[string]bool{}}
+			problems[key] = p
+		}
+		if entry.Time.Before(p.firstSeen) {
+			p.firstSeen = entry.Time
+		}
+		if entry.Difficulty != "" {
+			p.difficulty = strings.ToLower(entry.Difficulty)
+		}
+		if len(entry.Tags) != 0 {
+			p.tags = entry.Tags
+		}
+
+		if entry.Event != history.Submit {
+			continue
+		}
+
+		report.Submissions++
+		p.submitted = true
+		for _, tag := range entry.Tags {
+			topic, ok := topics[tag]
+			if !ok {
+				topic = &Topic{Tag: tag}
+				topics[tag] = topic
+			}
+			topic.Submissions++
+			if entry.IsAccepted() {
+				topic.Accepted++
+			}
+		}
+
+		if entry.IsAccepted() {
+			report.Accepted++
+			days[day(entry.Time.In(now.Location()))] = true
+			p.langs[entry.Lang] = true
+			if !p.isSolved() || entry.Time.Before(p.firstAccepted) {
+				p.firstAccepted = entry.Time
+			}
+		}
+	}
+
+	byDifficulty := map[string]uint64{}
+	byTag := map[string]uint64{}
+	byLang := map[string]uint64{}
+	var timesToAccept []time.Duration
+
+	for _, p := range problems {
+		if !p.isSolved() {
+			if p.submitted {
+				report.Attempted++
+			}
+			continue
+		}
+
+		report.Solved++
+		if p.difficulty != "" {
+			byDifficulty[p.difficulty]++
+		}
+		for _, tag := range p.tags {
+			byTag[tag]++
+		}
+		for lang := range p.langs {
+			byLang[lang]++
+		}
+		if elapsed := p.firstAccepted.Sub(p.firstSeen); elapsed > 0 {
+			timesToAccept = append(timesToAccept, elapsed)
+		}
+	}
+
+	report.ByDifficulty = sortCounts(byDifficulty)
+	report.ByTag = sortCounts(byTag)
+	report.ByLang = sortCounts(byLang)
+
+	if report.Submissions != 0 {
+		report.AcceptanceRate = float64(report.Accepted) / float64(report.Submissions)
+	}
+
+	report.CurrentStreak, report.LongestStreak = streaks(days, now)
+	report.MedianTimeToAccept = int64(median(timesToAccept) / time.Second)
+
+	report.WeakestTopics = []Topic{}
+	for _, topic := range topics {
+		if topic.Submissions < MinTopicSubmissions {
+			continue
+		}
+		topic.AcceptanceRate = float64(topic.Accepted) / float64(topic.Submissions)
+		report.WeakestTopics = append(report.WeakestTopics, *topic)
+	}
+	sort.Slice(report.WeakestTopics, func(i, j int) bool {
+		a, b := report.WeakestTopics[i], report.WeakestTopics[j]
+		if a.AcceptanceRate != b.AcceptanceRate {
+			return a.AcceptanceRate < b.AcceptanceRate
+		}
+		if a.Submissions != b.Submissions {
+			return a.Submissions > b.Submissions
+		}
+		return a.Tag < b.Tag
+	})
+	if len(report.WeakestTopics) > WeakestTopicsCount {
+		report.WeakestTopics = report.WeakestTopics[:WeakestTopicsCount]
+	}
+
+	return report
+}
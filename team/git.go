@@ -0,0 +1,156 @@
+package team
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VerdictTrailer is the git trailer recording the verdict of the solutions a
+// commit touches, e.g. `Tinycode-Verdict: accepted`
+const VerdictTrailer = "Tinycode-Verdict"
+
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Commit struct {
+	Hash     string
+	Author   Author
+	Time     time.Time
+	Trailers map[string]string // the last value of each key
+	Verdict  Verdict
+	Files    []string // relative to the root of the repository
+}
+
+// Git runs a git command in dir, returning its output
+func Git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// Toplevel returns the root of the repository enclosing dir
+func Toplevel(dir string) (string, error) {
+	output, err := Git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(strings.TrimSpace(string(output))), nil
+}
+
+// Self returns the author the user commits to the repository enclosing dir
+// as, mapped like the authors of Log
+func Self(dir string) (Author, error) {
+	name, err := Git(dir, "config", "user.name")
+	if err != nil {
+		return Author{}, err
+	}
+	email, err := Git(dir, "config", "user.email")
+	if err != nil {
+		return Author{}, err
+	}
+
+	contact := fmt.Sprintf("%s <%s>", strings.TrimSpace(string(name)), strings.TrimSpace(string(email)))
+	output, err := Git(dir, "check-mailmap", contact)
+	if err != nil {
+		return Author{}, err
+	}
+
+	mapped := strings.TrimSpace(string(output))
+	start, end := strings.LastIndex(mapped, "<"), strings.LastIndex(mapped, ">")
+	if start == -1 || end < start {
+		return Author{}, fmt.Errorf("unexpected git check-mailmap output: %q", mapped)
+	}
+	return Author{Name: strings.TrimSpace(mapped[:start]), Email: mapped[start+1 : end]}, nil
+}
+
+// Log returns the commits of the repository enclosing dir, newest first
+func Log(dir string) ([]Commit, error) {
+	format := "%x1e%H%x1f%aN%x1f%aE%x1f%aI%x1f%(trailers:only,unfold)%x1f"
+	output, err := Git(dir, "log", "--use-mailmap", "--name-only", "--format="+format)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log record: %q", record)
+		}
+
+		at, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, err
+		}
+
+		commit := Commit{
+			Hash:     fields[0],
+			Author:   Author{fields[1], fields[2]},
+			Time:     at,
+			Trailers: map[string]string{},
+		}
+
+		for _, line := range strings.Split(fields[4], "\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				commit.Trailers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+		commit.Verdict = ParseVerdict(commit.Trailers[VerdictTrailer])
+
+		for _, line := range strings.Split(fields[5], "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commit.Files = append(commit.Files, line)
+			}
+		}
+
+		commits = append(commits, commit)
+	}
+
+	return commits, nil
+}
+
+// History is what git knows of a file
+type History struct {
+	Added   Commit  // the first commit which touched the file
+	Verdict Verdict // the latest verdict recorded by a commit which touched it
+}
+
+// Histories returns the history of every file touched by commits, as returned
+// by Log
+func Histories(commits []Commit) map[string]History {
+	output := map[string]History{}
+
+	// oldest first, so that later commits override the verdict
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		for _, file := range commit.Files {
+			history, ok := output[file]
+			if !ok {
+				history = History{Added: commit, Verdict: Unknown}
+			}
+			if commit.Verdict != Unknown {
+				history.Verdict = commit.Verdict
+			}
+			output[file] = history
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,77 @@
+package team
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/htmlpage"
+	"html/template"
+	"io"
+	"strings"
+)
+
+const dateFormat = "January 2, 2006"
+
+// period is the human-friendly span of the report, until being exclusive
+func (report *Report) period() string {
+	return fmt.Sprintf("%s to %s", report.Since.Format(dateFormat), report.Until.AddDate(0, 0, -1).Format(dateFormat))
+}
+
+// escapeMarkdown keeps table cells from breaking out of their column
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// RenderMarkdown writes report as a Markdown table, e.g. for a wiki or a chat
+func RenderMarkdown(writer io.Writer, report Report) error {
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "# Leaderboard\n\n%s", report.period())
+	if report.Verified {
+		buf.WriteString(", accepted solutions only")
+	}
+	buf.WriteString("\n\n")
+
+	if len(report.Leaderboard) == 0 {
+		buf.WriteString("No solutions were added to the repository.\n")
+	} else {
+		buf.WriteString("| # | Author | Solved | Score | Languages |\n")
+		buf.WriteString("|--:|--------|-------:|------:|-----------|\n")
+		for _, entry := range report.Leaderboard {
+			fmt.Fprintf(&buf, "| %d | %s | %d | %d | %s |\n", entry.Rank, escapeMarkdown(entry.Author.Name), entry.Solved, entry.Score, escapeMarkdown(strings.Join(entry.Langs, ", ")))
+		}
+	}
+
+	_, err := io.WriteString(writer, buf.String())
+	return err
+}
+
+// htmlTemplate also lists the problems of each author, which the Markdown
+// table leaves out to stay readable in a chat
+const htmlTemplate = `{{define "title"}}tinycode leaderboard: {{.Period}}{{end}}
+{{define "style"}}table { margin-top: 1.5em; }
+td, th { padding: .4em .5em; border-bottom: 1px solid #eee; }
+td.problems { color: #777; font-size: .85em; }
+tr.first td { font-weight: bold; }
+{{end}}
+{{define "body"}}<h1>Leaderboard</h1>
+<p class="subtitle">{{.Period}}{{if .Report.Verified}}, accepted solutions only{{end}}</p>
+{{with .Report.Leaderboard}}<table>
+  <tr><th>#</th><th>author</th><th>solved</th><th>score</th><th>languages</th><th>problems</th></tr>{{range .}}
+  <tr{{if eq .Rank 1}} class="first"{{end}}><td class="count">{{.Rank}}</td><td title="{{.Author.Email}}">{{.Author.Name}}</td><td class="count">{{.Solved}}</td><td class="count">{{.Score}}</td><td>{{join .Langs ", "}}</td><td class="problems">{{join .Problems ", "}}</td></tr>{{end}}
+</table>{{else}}<p>No solutions were added to the repository.</p>{{end}}
+{{end}}`
+
+// RenderHtml writes the leaderboard of report as an HTML page, with the
+// problems each author solved
+func RenderHtml(writer io.Writer, report Report) error {
+	functions := template.FuncMap{
+		"join": strings.Join,
+	}
+
+	return htmlpage.Render(writer, "team", functions, htmlTemplate, struct {
+		Report Report
+		Period string
+	}{
+		report,
+		report.period(),
+	})
+}
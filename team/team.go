@@ -0,0 +1,178 @@
+// Package team builds leaderboards out of the solutions kept in a shared git
+// repository
+package team
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Verdict string
+
+const (
+	Accepted Verdict = "accepted"
+	Rejected         = "rejected"
+	Unknown          = "unknown"
+)
+
+func ParseVerdict(s string) Verdict {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "accepted":
+		return Accepted
+	case "rejected", "wrong_answer", "compile_error", "runtime_error", "time_limit_exceeded", "memory_limit_exceeded", "output_limit_exceeded", "other_error":
+		return Rejected
+	default:
+		return Unknown
+	}
+}
+
+type Solution struct {
+	Path       string            `json:"path"` // relative to the root of the repository
+	Provider   string            `json:"provider"`
+	Identity   map[string]string `json:"identity"`
+	Lang       string            `json:"lang"`
+	Difficulty string            `json:"difficulty,omitempty"`
+	Verdict    Verdict           `json:"verdict"`
+	Author     Author            `json:"author"`
+	Time       time.Time         `json:"time"` // of the commit which added it
+}
+
+// Problem identifies the problem of a solution, whatever its language
+func (solution *Solution) Problem() string {
+	return fmt.Sprintf("%s/%s/%s", solution.Provider, solution.Identity["contest"], solution.Identity["slug"])
+}
+
+// Weight is the score of solving a problem of the given difficulty
+func Weight(difficulty string) int {
+	switch strings.ToLower(difficulty) {
+	case "medium":
+		return 2
+	case "hard":
+		return 3
+	case "expert", "advanced":
+		return 4
+	default:
+		return 1
+	}
+}
+
+type Entry struct {
+	Rank     int      `json:"rank"`
+	Author   Author   `json:"author"`
+	Solved   int      `json:"solved"`
+	Score    int      `json:"score"`
+	Langs    []string `json:"langs"`
+	Problems []string `json:"problems"`
+}
+
+type Report struct {
+	Repo        string     `json:"repo"`
+	Since       time.Time  `json:"since"`
+	Until       time.Time  `json:"until"`
+	Verified    bool       `json:"verified"` // only accepted solutions are counted
+	Leaderboard []Entry    `json:"leaderboard"`
+	Solutions   []Solution `json:"solutions"`
+}
+
+// WeekStart is the Monday starting the week of t
+func WeekStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	offset := (int(start.Weekday()) + 6) % 7 // days since Monday
+	return start.AddDate(0, 0, -offset)
+}
+
+// counts is true if solution counts towards the leaderboard
+func counts(solution Solution, verified bool) bool {
+	if verified {
+		return solution.Verdict == Accepted
+	}
+	return solution.Verdict != Rejected
+}
+
+// NewReport ranks the authors of the solutions added between since and until
+func NewReport(repo string, solutions []Solution, since time.Time, until time.Time, verified bool) Report {
+	report := Report{
+		Repo:        repo,
+		Since:       since,
+		Until:       until,
+		Verified:    verified,
+		Leaderboard: []Entry{},
+		Solutions:   []Solution{},
+	}
+
+	type tally struct {
+		entry    Entry
+		problems map[string]bool
+		langs    map[string]bool
+	}
+	tallies := map[string]*tally{}
+
+	for _, solution := range solutions {
+		if solution.Time.Before(since) || !solution.Time.Before(until) {
+			continue
+		}
+		report.Solutions = append(report.Solutions, solution)
+
+		if !counts(solution, verified) {
+			continue
+		}
+
+		key := strings.ToLower(solution.Author.Email)
+		t, ok := tallies[key]
+		if !ok {
+			t = &tally{
+				entry:    Entry{Author: solution.Author},
+				problems: map[string]bool{},
+				langs:    map[string]bool{},
+			}
+			tallies[key] = t
+		}
+
+		t.langs[solution.Lang] = true
+		if problem := solution.Problem(); !t.problems[problem] {
+			t.problems[problem] = true
+			t.entry.Solved++
+			t.entry.Score += Weight(solution.Difficulty)
+			t.entry.Problems = append(t.entry.Problems, solution.Identity["slug"])
+		}
+	}
+
+	for _, t := range tallies {
+		for lang := range t.langs {
+			t.entry.Langs = append(t.entry.Langs, lang)
+		}
+		sort.Strings(t.entry.Langs)
+		sort.Strings(t.entry.Problems)
+		report.Leaderboard = append(report.Leaderboard, t.entry)
+	}
+
+	sort.Slice(report.Leaderboard, func(i, j int) bool {
+		a, b := report.Leaderboard[i], report.Leaderboard[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		return a.Author.Name < b.Author.Name
+	})
+
+	for i := range report.Leaderboard {
+		report.Leaderboard[i].Rank = i + 1
+		if i > 0 {
+			previous := report.Leaderboard[i-1]
+			if previous.Score == report.Leaderboard[i].Score && previous.Solved == report.Leaderboard[i].Solved {
+				report.Leaderboard[i].Rank = previous.Rank
+			}
+		}
+	}
+
+	sort.Slice(report.Solutions, func(i, j int) bool {
+		return report.Solutions[i].Time.Before(report.Solutions[j].Time)
+	})
+
+	return report
+}
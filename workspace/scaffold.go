@@ -0,0 +1,161 @@
+package workspace
+
+import (
+	"fmt"
+	"github.com/brokad/tinycode/provider"
+	"github.com/iancoleman/strcase"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	Gradle string = "gradle"
+	Maven         = "maven"
+)
+
+type Project struct {
+	Source string            // path to the solution file, relative to the problem directory
+	Files  map[string]string // other project files, relative to the problem directory
+}
+
+// Filename is the name of a standalone solution file for the problem slug
+func Filename(lang provider.Lang, slug string) string {
+	var filename string
+	switch lang.String() {
+	case provider.Swift, provider.Java:
+		filename = strcase.ToCamel(slug)
+	case provider.Rust:
+		filename = strings.ReplaceAll(slug, "-", "_")
+	default:
+		filename = slug
+	}
+	return fmt.Sprintf("%s.%s", filename, lang.Ext())
+}
+
+// javaClasses returns the name of the public class declared in source (which
+// has to match the file name) and the name of the class declaring `main`
+func javaClasses(source string) (string, string) {
+	publicClass := "Solution"
+	if matches := regexp.MustCompile("public\\s+class\\s+(\\w+)").FindStringSubmatch(source); len(matches) > 1 {
+		publicClass = matches[1]
+	}
+
+	mainClass := publicClass
+	if idx := strings.Index(source, "static void main"); idx != -1 {
+		declarations := regexp.MustCompile("class\\s+(\\w+)").FindAllStringSubmatch(source[:idx], -1)
+		if len(declarations) > 0 {
+			mainClass = declarations[len(declarations)-1][1]
+		}
+	}
+
+	return publicClass, mainClass
+}
+
+func cargoToml(name string) string {
+	return fmt.Sprintf(`[package]
+name = "%s"
+version = "0.1.0"
+edition = "2021"
+
+[dependencies]
+`, name)
+}
+
+func goMod(name string) string {
+	return fmt.Sprintf("module %s\n\ngo 1.18\n", name)
+}
+
+func cmakeLists(name string, lang string, source string) string {
+	var standard string
+	if lang == "CXX" {
+		standard = "set(CMAKE_CXX_STANDARD 17)\n"
+	} else {
+		standard = "set(CMAKE_C_STANDARD 11)\n"
+	}
+	return fmt.Sprintf(`cmake_minimum_required(VERSION 3.10)
+project(%s LANGUAGES %s)
+
+%s
+add_executable(%[1]s %[4]s)
+`, name, lang, standard, source)
+}
+
+func buildGradle(mainClass string) string {
+	return fmt.Sprintf(`plugins {
+    id 'application'
+}
+
+application {
+    mainClass = '%s'
+}
+`, mainClass)
+}
+
+func settingsGradle(name string) string {
+	return fmt.Sprintf("rootProject.name = '%s'\n", name)
+}
+
+func pomXml(name string, mainClass string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+
+  <groupId>tinycode</groupId>
+  <artifactId>%s</artifactId>
+  <version>0.1.0</version>
+
+  <properties>
+    <maven.compiler.source>17</maven.compiler.source>
+    <maven.compiler.target>17</maven.compiler.target>
+    <exec.mainClass>%s</exec.mainClass>
+  </properties>
+</project>
+`, name, mainClass)
+}
+
+// Scaffold lays out a buildable project for a solution written in lang. The
+// returned project's Source is where the encoded challenge (and hence its
+// submit region) should be written.
+func Scaffold(lang provider.Lang, name string, javaBuild string, source string) (*Project, error) {
+	name = strings.ReplaceAll(sanitizeSegment(name), ".", "-")
+	if name == "" {
+		return nil, fmt.Errorf("cannot scaffold a project without a name")
+	}
+	crate := strings.ReplaceAll(name, "-", "_")
+
+	project := Project{Files: map[string]string{}}
+
+	switch lang.String() {
+	case provider.Rust:
+		project.Source = path.Join("src", "main.rs")
+		project.Files["Cargo.toml"] = cargoToml(crate)
+	case provider.Golang:
+		project.Source = "main.go"
+		project.Files["go.mod"] = goMod(name)
+	case provider.Cpp, provider.Cpp14:
+		project.Source = "main.cpp"
+		project.Files["CMakeLists.txt"] = cmakeLists(crate, "CXX", project.Source)
+	case provider.C:
+		project.Source = "main.c"
+		project.Files["CMakeLists.txt"] = cmakeLists(crate, "C", project.Source)
+	case provider.Java, provider.Java8, provider.Java15:
+		publicClass, mainClass := javaClasses(source)
+		project.Source = path.Join("src", "main", "java", fmt.Sprintf("%s.java", publicClass))
+		switch javaBuild {
+		case Gradle:
+			project.Files["build.gradle"] = buildGradle(mainClass)
+			project.Files["settings.gradle"] = settingsGradle(name)
+		case Maven:
+			project.Files["pom.xml"] = pomXml(name, mainClass)
+		default:
+			return nil, fmt.Errorf("unknown java-build: %s (must be gradle or maven)", javaBuild)
+		}
+	default:
+		project.Source = Filename(lang, name)
+	}
+
+	return &project, nil
+}
@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/brokad/tinycode/autocommit"
+	"github.com/brokad/tinycode/hooks"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const ConfigFilename = ".tinycode.toml"
+
+const DefaultLayout = "{provider}/{difficulty}/{id}-{slug}/"
+
+type Config struct {
+	Provider  string            `mapstructure:"provider"`
+	Lang      string            `mapstructure:"lang"`
+	Layout    string            `mapstructure:"layout"`
+	JavaBuild string            `mapstructure:"java-build"`
+	Hooks     hooks.Config      `mapstructure:"hooks"`
+	Git       autocommit.Config `mapstructure:"git"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Provider:  "",
+		Lang:      "",
+		Layout:    DefaultLayout,
+		JavaBuild: Gradle,
+	}
+}
+
+type Workspace struct {
+	Root   string
+	Config Config
+}
+
+func newViper(root string) *viper.Viper {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(root, ConfigFilename))
+	v.SetConfigType("toml")
+	v.SetDefault("layout", DefaultLayout)
+	v.SetDefault("java-build", Gradle)
+	return v
+}
+
+func Load(root string) (*Workspace, error) {
+	v := newViper(root)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+
+	return &Workspace{Root: root, Config: config}, nil
+}
+
+// Find looks for a workspace configuration in dir and its parents
+func Find(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		_, err := os.Stat(filepath.Join(dir, ConfigFilename))
+		if err == nil {
+			return Load(dir)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("no %s found in any parent directory", ConfigFilename)
+		}
+		dir = parent
+	}
+}
+
+func Init(root string, config Config) (*Workspace, error) {
+	if err := os.MkdirAll(root, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(root, ConfigFilename)
+	if _, err := os.Stat(configPath); err == nil {
+		return nil, fmt.Errorf("workspace already initialized: %s", configPath)
+	}
+
+	v := newViper(root)
+	v.Set("provider", config.Provider)
+	v.Set("lang", config.Lang)
+	v.Set("layout", config.Layout)
+	v.Set("java-build", config.JavaBuild)
+
+	if err := v.WriteConfigAs(configPath); err != nil {
+		return nil, err
+	}
+
+	return &Workspace{Root: root, Config: config}, nil
+}
+
+func sanitizeSegment(s string) string {
+	re := regexp.MustCompile("[^\\w.-]+")
+	s = re.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(s, "-_.")
+}
+
+// ProblemDir expands the layout template of the workspace with vars, e.g.
+// `{provider}/{difficulty}/{id}-{slug}/`. Placeholders for which no value is
+// known expand to nothing and the separators around them are dropped.
+func (ws *Workspace) ProblemDir(vars map[string]string) (string, error) {
+	re := regexp.MustCompile("{(\\w+)}")
+
+	var segments []string
+	for _, segment := range strings.Split(ws.Config.Layout, "/") {
+		expanded := re.ReplaceAllStringFunc(segment, func(m string) string {
+			return vars[m[1:len(m)-1]]
+		})
+		if expanded = sanitizeSegment(expanded); expanded != "" {
+			segments = append(segments, expanded)
+		}
+	}
+
+	if len(segments) == 0 {
+		return "", fmt.Errorf("layout expands to an empty path: %s", ws.Config.Layout)
+	}
+
+	return filepath.Join(append([]string{ws.Root}, segments...)...), nil
+}